@@ -1,8 +1,9 @@
 package main
 
 import (
-	"EfficientAndLowStroageSSE/FB_RSSE"
 	"EfficientAndLowStroageSSE/VH_RSSE/OurScheme"
+	"EfficientAndLowStroageSSE/config"
+	"EfficientAndLowStroageSSE/rsse"
 	"bufio"
 	"encoding/csv"
 	"fmt"
@@ -30,6 +31,12 @@ func main() {
 	LValues := []int{6424} // 设置 L 值范围
 	k := 999999            // 设置最大查询次数
 	resultCounts := 200    // 结果存储的有效查询次数
+	// OurScheme 的结果规模隐藏模式由 config.Padding 选择，默认不填充
+	padding, err := OurScheme.ParsePadding(config.Padding)
+	if err != nil {
+		fmt.Printf("无法解析填充模式: %v\n", err)
+		return
+	}
 
 	// 结果存储目录
 	resultsDir := "results"
@@ -57,55 +64,37 @@ func main() {
 
 		// 遍历每个 L 值
 		for _, L := range LValues {
-			// 初始化 FB_RSSE 和 OurScheme 的对象
-			ours := OurScheme.Setup(L)
-			fb_rsse := FB_RSSE.Setup(FB_BsLen)
-
-			// 测量 BuildIndex 时间（OurScheme）
-			startTime = time.Now()
-			err = ours.BuildIndex(invertedIndex, sortedKeywords)
-			if err != nil {
-				fmt.Printf("OurScheme BuildIndex 返回错误: %v\n", err)
-				return
-			}
-			buildIndexDurationOurs := time.Since(startTime).Nanoseconds()
-
-			// 测量 BuildIndex 时间（FB_RSSE）
-			startTime = time.Now()
-			err = fb_rsse.BuildIndex(invertedIndex, sortedKeywords)
-			if err != nil {
-				fmt.Printf("FB_RSSE BuildIndex 返回错误: %v\n", err)
-				return
-			}
-			buildIndexDurationFB := time.Since(startTime).Nanoseconds()
-
-			// 结果文件路径（CSV格式）
-			resultFilePathOurs := fmt.Sprintf("%s/comparison_result_m_%d_L_%d_OurScheme.csv", resultsDir, indexNum[fileIndex], L)
-			resultFilePathFB := fmt.Sprintf("%s/comparison_result_m_%d_L_%d_FB_RSSE.csv", resultsDir, indexNum[fileIndex], L)
+			// 初始化 OurScheme 和 FB_RSSE 的对象，两者通过 rsse.Scheme 统一调用
+			schemes := []rsse.Scheme{rsse.NewOurSchemePadded(L, padding, config.BucketSize), rsse.NewFBRSSE(FB_BsLen)}
+			buildIndexDurations := make([]int64, len(schemes))
+			writers := make([]*csv.Writer, len(schemes))
+			resultFilePaths := make([]string, len(schemes))
+
+			for j, scheme := range schemes {
+				// 测量 BuildIndex 时间
+				startTime = time.Now()
+				err = scheme.BuildIndex(invertedIndex, sortedKeywords)
+				if err != nil {
+					fmt.Printf("%s BuildIndex 返回错误: %v\n", scheme.Name(), err)
+					return
+				}
+				buildIndexDurations[j] = time.Since(startTime).Nanoseconds()
 
-			// 创建并写入结果文件（OurScheme）
-			resultFile, err := os.Create(resultFilePathOurs)
-			if err != nil {
-				fmt.Printf("无法创建结果文件 %s: %v\n", resultFilePathOurs, err)
-				return
-			}
-			defer resultFile.Close()
+				// 结果文件路径（CSV格式）
+				resultFilePaths[j] = fmt.Sprintf("%s/comparison_result_m_%d_L_%d_%s.csv", resultsDir, indexNum[fileIndex], L, scheme.Name())
 
-			writer := csv.NewWriter(resultFile)
-			defer writer.Flush()
-			writer.Write([]string{"Iteration", "Left", "Right", "RangeWidth", "BuildIndex(ns)", "GenToken(ns)", "SearchTokens(ns)", "LocalSearch(ns)", "ClientTimeCost(ns)", "number of tokens"})
+				// 创建并写入结果文件
+				resultFile, err := os.Create(resultFilePaths[j])
+				if err != nil {
+					fmt.Printf("无法创建结果文件 %s: %v\n", resultFilePaths[j], err)
+					return
+				}
+				defer resultFile.Close()
 
-			// 创建并写入结果文件（FB_RSSE）
-			resultFile, err = os.Create(resultFilePathFB)
-			if err != nil {
-				fmt.Printf("无法创建结果文件 %s: %v\n", resultFilePathFB, err)
-				return
+				writers[j] = csv.NewWriter(resultFile)
+				defer writers[j].Flush()
+				writers[j].Write([]string{"Iteration", "Left", "Right", "RangeWidth", "BuildIndex(ns)", "GenToken(ns)", "SearchTokens(ns)", "LocalSearch(ns)", "ClientTimeCost(ns)", "number of tokens", "Results", "PaddedResults", "PaddingOverhead"})
 			}
-			defer resultFile.Close()
-
-			writerFB := csv.NewWriter(resultFile)
-			defer writerFB.Flush()
-			writerFB.Write([]string{"Iteration", "Left", "Right", "RangeWidth", "BuildIndex(ns)", "GenToken(ns)", "SearchTokens(ns)", "LocalSearch(ns)", "ClientTimeCost(ns)", "number of tokens"})
 
 			// 开始测试
 			for _, r := range ranges {
@@ -114,31 +103,31 @@ func main() {
 				for i := 0; i < k; i++ { // 生成查询区间并计算区间宽度
 					queryRange, rangeWidth := generateQueryRangeWithWidth(sortedKeywords, r)
 
-					// 测量 GenToken 时间（OurScheme）
-					startTime = time.Now()
-					tokensOurs, err := ours.GenToken(queryRange)
-					if err != nil {
-						fmt.Printf("OurScheme GenToken 返回错误: %v\n", err)
-						return
-					}
-					genTokenDurationOurs := time.Since(startTime).Nanoseconds()
-
-					// 测量 GenToken 时间（FB_RSSE）
-					startTime = time.Now()
-					K_set, ST_set, c_set, err := fb_rsse.GenToken(queryRange, sortedKeywords)
-					if err != nil {
-						fmt.Printf("FB_RSSE GenToken 返回错误: %v\n", err)
-						return
+					// 测量各方案的 GenToken 时间
+					tokens := make([]rsse.Token, len(schemes))
+					genTokenDurations := make([]int64, len(schemes))
+					empty := false
+					for j, scheme := range schemes {
+						startTime = time.Now()
+						tokens[j], err = scheme.GenToken(queryRange)
+						if err != nil {
+							fmt.Printf("%s GenToken 返回错误: %v\n", scheme.Name(), err)
+							return
+						}
+						genTokenDurations[j] = time.Since(startTime).Nanoseconds()
+						if tokens[j].Len() == 0 {
+							empty = true
+						}
 					}
-					genTokenDurationFB := time.Since(startTime).Nanoseconds()
 
 					// 如果 tokens 为空，跳过本次循环
-					if len(tokensOurs) == 0 || len(c_set) == 0 {
+					if empty {
 						searchTokensDuration := 0
 						localSearchDuration := 0
 						clientTimeCost := searchTokensDuration + localSearchDuration
-						writer.Write([]string{fmt.Sprintf("%d", i+1), queryRange[0], queryRange[1], fmt.Sprintf("%d", rangeWidth), fmt.Sprintf("%d", buildIndexDurationOurs), fmt.Sprintf("%d", genTokenDurationOurs), fmt.Sprintf("%d", searchTokensDuration), fmt.Sprintf("%d", localSearchDuration), fmt.Sprintf("%d", clientTimeCost), fmt.Sprintf("%d", len(tokensOurs))})
-						writerFB.Write([]string{fmt.Sprintf("%d", i+1), queryRange[0], queryRange[1], fmt.Sprintf("%d", rangeWidth), fmt.Sprintf("%d", buildIndexDurationFB), fmt.Sprintf("%d", genTokenDurationFB), fmt.Sprintf("%d", searchTokensDuration), fmt.Sprintf("%d", localSearchDuration), fmt.Sprintf("%d", clientTimeCost), fmt.Sprintf("%d", len(c_set))})
+						for j := range schemes {
+							writers[j].Write([]string{fmt.Sprintf("%d", i+1), queryRange[0], queryRange[1], fmt.Sprintf("%d", rangeWidth), fmt.Sprintf("%d", buildIndexDurations[j]), fmt.Sprintf("%d", genTokenDurations[j]), fmt.Sprintf("%d", searchTokensDuration), fmt.Sprintf("%d", localSearchDuration), fmt.Sprintf("%d", clientTimeCost), fmt.Sprintf("%d", tokens[j].Len()), "0", "0", "0"})
+						}
 						fmt.Println("Tokens are empty, skipping iteration")
 						continue
 					}
@@ -146,45 +135,37 @@ func main() {
 					// 计数有效查询
 					validCount++
 
-					// 测量 SearchTokens 时间（OurScheme）
-					startTime = time.Now()
-					searchResultOurs := ours.SearchTokens(tokensOurs)
-					searchTokensDurationOurs := time.Since(startTime).Nanoseconds()
-
-					// 测量 ServerSearch 时间（FB_RSSE）
-					startTime = time.Now()
-					searchResultFB, _ := fb_rsse.ServerSearch(K_set, ST_set, c_set)
-					searchTokensDurationFB := time.Since(startTime).Nanoseconds()
-
-					// 测量 LocalSearch 时间（OurScheme）
-					startTime = time.Now()
-					_, err = ours.LocalSearch(searchResultOurs, tokensOurs)
-					if err != nil {
-						fmt.Printf("OurScheme LocalSearch 返回错误: %v\n", err)
-						return
-					}
-					localSearchDurationOurs := time.Since(startTime).Nanoseconds()
-
-					// 测量 LocalParse 时间（FB_RSSE）
-					startTime = time.Now()
-					_, err = fb_rsse.LocalParse(K_set, c_set, searchResultFB)
-					if err != nil {
-						fmt.Printf("FB_RSSE LocalSearch 返回错误: %v\n", err)
-						return
+					for j, scheme := range schemes {
+						// 测量服务器搜索时间
+						startTime = time.Now()
+						searchResult, err := scheme.ServerSearch(tokens[j])
+						if err != nil {
+							fmt.Printf("%s ServerSearch 返回错误: %v\n", scheme.Name(), err)
+							return
+						}
+						searchTokensDuration := time.Since(startTime).Nanoseconds()
+
+						// 测量客户端解析时间，支持填充的方案包含填充与去除虚拟文件 ID，其余方案的填充后数量即结果数量
+						startTime = time.Now()
+						var results, padded []int
+						if padder, ok := scheme.(rsse.Padder); ok {
+							results, padded, err = padder.ResolvePadded(tokens[j], searchResult)
+						} else {
+							results, err = scheme.Resolve(tokens[j], searchResult)
+							padded = results
+						}
+						if err != nil {
+							fmt.Printf("%s LocalSearch 返回错误: %v\n", scheme.Name(), err)
+							return
+						}
+						localSearchDuration := time.Since(startTime).Nanoseconds()
+
+						// 计算 ClientTimeCost
+						clientTimeCost := genTokenDurations[j] + localSearchDuration
+
+						// 写入每次实验的耗时记录
+						writers[j].Write([]string{fmt.Sprintf("%d", i+1), queryRange[0], queryRange[1], fmt.Sprintf("%d", rangeWidth), fmt.Sprintf("%d", buildIndexDurations[j]), fmt.Sprintf("%d", genTokenDurations[j]), fmt.Sprintf("%d", searchTokensDuration), fmt.Sprintf("%d", localSearchDuration), fmt.Sprintf("%d", clientTimeCost), fmt.Sprintf("%d", tokens[j].Len()), fmt.Sprintf("%d", len(results)), fmt.Sprintf("%d", len(padded)), fmt.Sprintf("%d", len(padded)-len(results))})
 					}
-					localSearchDurationFB := time.Since(startTime).Nanoseconds()
-
-					// 计算 ClientTimeCost（OurScheme）
-					clientTimeCostOurs := genTokenDurationOurs + localSearchDurationOurs
-
-					// 计算 ClientTimeCost（FB_RSSE）
-					clientTimeCostFB := genTokenDurationFB + localSearchDurationFB
-
-					// 写入每次实验的耗时记录（OurScheme）
-					writer.Write([]string{fmt.Sprintf("%d", i+1), queryRange[0], queryRange[1], fmt.Sprintf("%d", rangeWidth), fmt.Sprintf("%d", buildIndexDurationOurs), fmt.Sprintf("%d", genTokenDurationOurs), fmt.Sprintf("%d", searchTokensDurationOurs), fmt.Sprintf("%d", localSearchDurationOurs), fmt.Sprintf("%d", clientTimeCostOurs), fmt.Sprintf("%d", len(tokensOurs))})
-
-					// 写入每次实验的耗时记录（FB_RSSE）
-					writerFB.Write([]string{fmt.Sprintf("%d", i+1), queryRange[0], queryRange[1], fmt.Sprintf("%d", rangeWidth), fmt.Sprintf("%d", buildIndexDurationFB), fmt.Sprintf("%d", genTokenDurationFB), fmt.Sprintf("%d", searchTokensDurationFB), fmt.Sprintf("%d", localSearchDurationFB), fmt.Sprintf("%d", clientTimeCostFB), fmt.Sprintf("%d", len(c_set))})
 
 					// 如果有效查询次数达到 300 次，停止循环
 					if validCount >= resultCounts {
@@ -195,8 +176,9 @@ func main() {
 			}
 
 			// 打印完成信息
-			fmt.Printf("完成文件: %s, L: %d, 结果存储于: %s\n", file, L, resultFilePathOurs)
-			fmt.Printf("完成文件: %s, L: %d, 结果存储于: %s\n", file, L, resultFilePathFB)
+			for _, resultFilePath := range resultFilePaths {
+				fmt.Printf("完成文件: %s, L: %d, 结果存储于: %s\n", file, L, resultFilePath)
+			}
 		}
 	}
 }
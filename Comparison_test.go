@@ -110,7 +110,7 @@ func TestComparisonTotal(t *testing.T) {
 
 					// 测量 GenToken 时间（OurScheme）
 					startTime = time.Now()
-					tokensOurs, err := ours.GenToken(queryRange)
+					queryOurs, err := ours.GenToken(queryRange)
 					if err != nil {
 						t.Fatalf("OurScheme GenToken 返回错误: %v", err)
 					}
@@ -125,7 +125,7 @@ func TestComparisonTotal(t *testing.T) {
 					genTokenDurationFB := time.Since(startTime).Nanoseconds()
 
 					// 如果 tokens 为空，跳过本次循环
-					if len(tokensOurs) == 0 || len(c_set) == 0 {
+					if len(queryOurs.Tokens) == 0 || len(c_set) == 0 {
 						searchTokensDuration := 0
 						localSearchDuration := 0
 						clientTimeCost := searchTokensDuration + localSearchDuration
@@ -140,7 +140,7 @@ func TestComparisonTotal(t *testing.T) {
 
 					// 测量 SearchTokens 时间（OurScheme）
 					startTime = time.Now()
-					searchResultOurs := ours.SearchTokens(tokensOurs)
+					searchResultOurs := ours.SearchTokens(queryOurs)
 					searchTokensDurationOurs := time.Since(startTime).Nanoseconds()
 
 					// 测量 ServerSearch 时间（FB_RSSE）
@@ -150,7 +150,7 @@ func TestComparisonTotal(t *testing.T) {
 
 					// 测量 LocalSearch 时间（OurScheme）
 					startTime = time.Now()
-					_, err = ours.LocalSearch(searchResultOurs, tokensOurs)
+					_, err = ours.LocalSearch(searchResultOurs, queryOurs)
 					if err != nil {
 						t.Fatalf("OurScheme LocalSearch 返回错误: %v", err)
 					}
@@ -381,9 +381,9 @@ func TestOurSchemeBuildIndex_storage_metadata(t *testing.T) {
 			// 打印构建时间
 			fmt.Printf("文件: %s, L: %d, BuildIndex耗时: %d 纳秒\n", file, L, buildIndexDurationOurs)
 
-			// 打印 EDB、LocalTree、ClusterFlist 和 ClusterKlist 的长度
+			// 打印 EDB、分区边界、ClusterFlist 和 ClusterKlist 的长度
 			fmt.Println("\nEDB 长度:", len(ours.EDB))
-			fmt.Println("LocalTree 长度:", len(ours.LocalTree))
+			fmt.Println("分区边界数量:", len(ours.Boundaries()))
 			// 计算 ClusterFlist 和 ClusterKlist 的总元素数量
 			clusterFlistTotal := countNestedElements(ours.ClusterFlist)
 			clusterKlistTotal := countNestedElements(ours.ClusterKlist)
@@ -395,7 +395,6 @@ func TestOurSchemeBuildIndex_storage_metadata(t *testing.T) {
 			//// 计算这些结构的内存占用
 			//fmt.Printf("\n内存占用 (KB/MB):\n")
 			//printMemoryUsage(ours.EDB, "EDB")
-			//printMemoryUsage(ours.LocalTree, "LocalTree")
 			//printMemoryUsage(ours.ClusterFlist, "ClusterFlist")
 			//printMemoryUsage(ours.ClusterKlist, "ClusterKlist")
 		}
@@ -466,13 +465,10 @@ func TestOurSchemeBuildIndex_storage(t *testing.T) {
 			// 打印构建时间
 			fmt.Printf("文件: %s, L: %d, BuildIndex耗时: %d 纳秒\n", file, L, buildIndexDurationOurs)
 
-			////打印 EDB、LocalTree、ClusterFlist 和 ClusterKlist 的值
+			////打印 EDB、ClusterFlist 和 ClusterKlist 的值
 			//fmt.Println("\nEDB (加密倒排索引):")
 			//printMapValues(ours.EDB)
 			//
-			//fmt.Println("\nLocalTree (本地树):")
-			//printMapValues(ours.LocalTree)
-			//
 			//fmt.Println("\nClusterFlist (分区文件列表):")
 			//printNestedSliceValues(ours.ClusterFlist)
 			//
@@ -482,7 +478,6 @@ func TestOurSchemeBuildIndex_storage(t *testing.T) {
 			// 计算这些结构的内存占用
 			fmt.Printf("\n内存占用 (KB/MB):\n")
 			printMemoryUsage(ours.EDB, "EDB")
-			printMemoryUsage(ours.LocalTree, "LocalTree")
 			printMemoryUsage(ours.ClusterFlist, "ClusterFlist")
 			printMemoryUsage(ours.ClusterKlist, "ClusterKlist")
 		}
@@ -696,14 +691,14 @@ func TestSelfTotal(t *testing.T) {
 
 						// 测量 GenToken 时间（OurScheme）
 						startTime = time.Now()
-						tokensOurs, err := ours.GenToken(queryRange)
+						queryOurs, err := ours.GenToken(queryRange)
 						if err != nil {
 							t.Fatalf("OurScheme GenToken 返回错误: %v", err)
 						}
 						genTokenDurationOurs := time.Since(startTime).Nanoseconds()
 
 						// 如果 tokens 为空，跳过本次循环
-						if len(tokensOurs) == 0 {
+						if len(queryOurs.Tokens) == 0 {
 							searchTokensDuration := 0
 							localSearchDuration := 0
 							clientTimeCost := searchTokensDuration + localSearchDuration
@@ -717,12 +712,12 @@ func TestSelfTotal(t *testing.T) {
 
 						// 测量 SearchTokens 时间（OurScheme）
 						startTime = time.Now()
-						searchResultOurs := ours.SearchTokens(tokensOurs)
+						searchResultOurs := ours.SearchTokens(queryOurs)
 						searchTokensDurationOurs := time.Since(startTime).Nanoseconds()
 
 						// 测量 LocalSearch 时间（OurScheme）
 						startTime = time.Now()
-						_, err = ours.LocalSearch(searchResultOurs, tokensOurs)
+						_, err = ours.LocalSearch(searchResultOurs, queryOurs)
 						if err != nil {
 							t.Fatalf("OurScheme LocalSearch 返回错误: %v", err)
 						}
@@ -849,7 +844,7 @@ func TestComparison(t *testing.T) {
 
 				// 测量 GenToken 时间（OurScheme）
 				startTime = time.Now()
-				tokensOurs, err := ours.GenToken(queryRange)
+				queryOurs, err := ours.GenToken(queryRange)
 				if err != nil {
 					t.Fatalf("OurScheme GenToken 返回错误: %v", err)
 				}
@@ -864,7 +859,7 @@ func TestComparison(t *testing.T) {
 				genTokenDurationFB := time.Since(startTime).Nanoseconds()
 
 				// 如果 tokens 为空，跳过本次循环
-				if len(tokensOurs) == 0 || len(c_set) == 0 {
+				if len(queryOurs.Tokens) == 0 || len(c_set) == 0 {
 					searchTokensDuration := 0
 					localSearchDuration := 0
 					clientTimeCost := searchTokensDuration + localSearchDuration
@@ -879,7 +874,7 @@ func TestComparison(t *testing.T) {
 
 				// 测量 SearchTokens 时间（OurScheme）
 				startTime = time.Now()
-				searchResultOurs := ours.SearchTokens(tokensOurs)
+				searchResultOurs := ours.SearchTokens(queryOurs)
 				searchTokensDurationOurs := time.Since(startTime).Nanoseconds()
 
 				// 测量 ServerSearch 时间（FB_RSSE）
@@ -889,7 +884,7 @@ func TestComparison(t *testing.T) {
 
 				// 测量 LocalSearch 时间（OurScheme）
 				startTime = time.Now()
-				_, err = ours.LocalSearch(searchResultOurs, tokensOurs)
+				_, err = ours.LocalSearch(searchResultOurs, queryOurs)
 				if err != nil {
 					t.Fatalf("OurScheme LocalSearch 返回错误: %v", err)
 				}
@@ -2,12 +2,18 @@ package FB_RSSE
 
 import (
 	"EfficientAndLowStroageSSE/config"
+	"EfficientAndLowStroageSSE/storage"
+	"bytes"
+	"crypto/rand"
 	"crypto/sha256"
+	"encoding/gob"
 	"fmt"
-	"math"
+	"io"
 	"math/big"
-	"math/rand"
+	"math/bits"
 	"runtime"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -15,11 +21,13 @@ import (
 type Data struct {
 	BigIntValue *big.Int
 	ByteValue   []byte
+	Tag         []byte // 可验证模式下条目的 MAC，绑定 UT、条目在链上的序号与条目内容
 }
 
 type Counter struct {
 	tokens []byte
 	c      int
+	epoch  int // 节点被合并的次数，每次合并后更换 K_w
 }
 
 type bitmap struct {
@@ -43,6 +51,19 @@ type SystemParameters struct {
 	LocalTree     map[string][]int64  // BRC tree
 	localTreeCode map[string]string   // BRC tree
 	TreeHeight    int
+	// Domain 关键词的整数值域，为 nil 时取建立索引时关键词的最小值与最大值
+	Domain *Domain
+	domain Domain // 建立索引时实际使用的值域
+	// PlaintextAblation 基准测试消融：Enc/Dec 不做加密，EDB 中直接存储明文位图，
+	// 仅用于衡量加密本身的开销，不提供任何安全性
+	PlaintextAblation bool
+	// Verifiable 可验证搜索模式：每个条目附带 MAC，ServerSearchProof 随结果返回链上的全部条目，
+	// LocalParseVerified 沿令牌链验证条目数、MAC 与密文之和，不一致时返回 *VerificationError。
+	// 须在 BuildIndex 之前设置
+	Verifiable bool
+	// files 客户端记录的每个关键词（叶子节点编码）当前包含的文件位图，用于拒绝无效的删除；
+	// 由 BuildIndex 建立，为 nil 时（BuildIndexMock 等实验用的构建方式）不检查
+	files map[string]*big.Int
 }
 
 // input 是输入数据，返回伪随机的输出
@@ -58,6 +79,30 @@ func (sp *SystemParameters) PRF(input []byte) []byte {
 	return sp.H1(combined)
 }
 
+// SetupPlaintextAblation 初始化不加密位图的系统参数，仅用于基准测试中的消融对比
+func SetupPlaintextAblation(L int) *SystemParameters {
+	sp := Setup(L)
+	sp.PlaintextAblation = true
+	return sp
+}
+
+// genSK 计算节点第 c 个条目的加密密钥 sk_c ← H1(K'_w, c)
+// K'_w 由系统密钥从 K_w 派生，服务器即使拿到查询令牌中的 K_w 也无法计算 sk_c
+func (sp *SystemParameters) genSK(K_w []byte, c int) *big.Int {
+	K_w_prime := sp.PRF(append([]byte("sk"), K_w...))
+	c_str_Array := []byte(strconv.Itoa(c))
+	return new(big.Int).SetBytes(sp.H1(append(K_w_prime, c_str_Array...)))
+}
+
+// nodeKey 计算节点在第 epoch 轮合并后的 K_w，epoch 为 0 时即 F_K(w)
+// 合并会把计数器重置为 0，更换 K_w 保证重置后的 sk_c 与 UT 不会与旧条目重复
+func (sp *SystemParameters) nodeKey(code string, epoch int) []byte {
+	if epoch == 0 {
+		return sp.PRF([]byte(code))
+	}
+	return sp.PRF([]byte(code + "/" + strconv.Itoa(epoch)))
+}
+
 // Setup 初始化系统参数
 func Setup(L int) *SystemParameters {
 	lambda := 256
@@ -95,6 +140,14 @@ func Setup(L int) *SystemParameters {
 
 // BuildIndex 构建倒排索引
 func (sp *SystemParameters) BuildDB(invertedIndex map[string][]int) (int, error) {
+	// 位图在模 n = 2^BsLength 下加密，超出位图长度的文件 ID 会在加密后丢失
+	for keyword, docIDs := range invertedIndex {
+		for _, id := range docIDs {
+			if id < 0 || id >= sp.BsLength {
+				return 0, fmt.Errorf("关键词 %s 的文件 ID %d 超出位图长度 %d", keyword, id, sp.BsLength)
+			}
+		}
+	}
 	// 创建一个大整数表示位图
 	for keyword, docIDs := range invertedIndex {
 		code := sp.localTreeCode[keyword]
@@ -124,39 +177,37 @@ func (sp *SystemParameters) BuildDB(invertedIndex map[string][]int) (int, error)
 
 // BuildIndex 构建倒排索引
 func (sp *SystemParameters) BuildIndex(invertedIndex map[string][]int, sortedKeywords []string) error {
-	sp.TreeHeight = int(math.Ceil(math.Log2(float64(len(invertedIndex)))))
-	// 构建 LocalTree
-	sp.buildLocalTree(sortedKeywords)
+	// 构建 LocalTree，树高由关键词值域决定
+	if err := sp.buildLocalTree(sortedKeywords); err != nil {
+		return err
+	}
 	_, err := sp.BuildDB(invertedIndex)
 
 	if err != nil {
 		return err
 	}
+	if err := sp.recordFiles(invertedIndex); err != nil {
+		return err
+	}
 	for tempCode, tempBitmap := range sp.DB {
 		//加密索引
 		K_w := sp.PRF([]byte(tempCode))
 		ST_c, _ := sp.GenerateRandom()
 		ST_cplus1, _ := sp.GenerateRandom()
-		c := tempBitmap.c
-		// 将 int 转换为 string
-		c_str := strconv.Itoa(c)
-		// 将 string 转换为 []byte
-		c_str_Array := []byte(c_str)
+		// 构建阶段每个节点只写入一个条目，计数器从 0 开始
+		c := 0
 		//将DB中的计数器值和新计算的令牌值存在CT
 		sp.CT[tempCode] = Counter{c: c, tokens: ST_cplus1}
-		sk := sp.H1(append(K_w, c_str_Array...))
+		sk := sp.genSK(K_w, c)
 		UT_cplus1 := sp.H1(append(K_w, ST_cplus1...))
-		encBitmap := sp.Enc(new(big.Int).SetBytes(sk), tempBitmap.bs)
+		encBitmap := sp.Enc(sk, tempBitmap.bs)
 
 		//fmt.Printf("Build Index tempCode: %s: ", tempCode)
 		//PrintLowestBits(encBitmap, 50)
 
 		C_ST, _ := XOR(UT_cplus1, ST_c)
 		//fmt.Printf("C_ST: %x\n", C_ST)
-		sp.EDB[string(UT_cplus1)] = Data{
-			BigIntValue: encBitmap,
-			ByteValue:   C_ST,
-		}
+		sp.storeEntry(UT_cplus1, c, encBitmap, C_ST)
 
 	}
 
@@ -194,9 +245,12 @@ func (sp *SystemParameters) BuildDBMock1(invertedIndex map[string][]int) (int, e
 
 // BuildIndex 构建倒排索引
 func (sp *SystemParameters) BuildIndexMock1(invertedIndex map[string][]int, sortedKeywords []string) error {
-	sp.TreeHeight = int(math.Ceil(math.Log2(float64(len(invertedIndex)))))
-	// 构建 LocalTree
-	sp.buildLocalTree(sortedKeywords)
+	// 构建 LocalTree，树高由关键词值域决定
+	if err := sp.buildLocalTree(sortedKeywords); err != nil {
+		return err
+	}
+	// 实验用的构建方式不记录文件，删除时不检查
+	sp.files = nil
 	_, err := sp.BuildDBMock(invertedIndex)
 
 	if err != nil {
@@ -207,26 +261,20 @@ func (sp *SystemParameters) BuildIndexMock1(invertedIndex map[string][]int, sort
 		K_w := sp.PRF([]byte(tempCode))
 		ST_c, _ := sp.GenerateRandom()
 		ST_cplus1, _ := sp.GenerateRandom()
-		c := tempBitmap.c
-		// 将 int 转换为 string
-		c_str := strconv.Itoa(c)
-		// 将 string 转换为 []byte
-		c_str_Array := []byte(c_str)
+		// 构建阶段每个节点只写入一个条目，计数器从 0 开始
+		c := 0
 		//将DB中的计数器值和新计算的令牌值存在CT
 		sp.CT[tempCode] = Counter{c: c, tokens: ST_cplus1}
-		sk := sp.H1(append(K_w, c_str_Array...))
+		sk := sp.genSK(K_w, c)
 		UT_cplus1 := sp.H1(append(K_w, ST_cplus1...))
-		encBitmap := sp.Enc(new(big.Int).SetBytes(sk), tempBitmap.bs)
+		encBitmap := sp.Enc(sk, tempBitmap.bs)
 
 		//fmt.Printf("Build Index tempCode: %s: ", tempCode)
 		//PrintLowestBits(encBitmap, 50)
 
 		C_ST, _ := XOR(UT_cplus1, ST_c)
 		//fmt.Printf("C_ST: %x\n", C_ST)
-		sp.EDB[string(UT_cplus1)] = Data{
-			BigIntValue: encBitmap,
-			ByteValue:   C_ST,
-		}
+		sp.storeEntry(UT_cplus1, c, encBitmap, C_ST)
 
 	}
 
@@ -273,8 +321,12 @@ func (sp *SystemParameters) BuildDBMock(invertedIndex map[string][]int) (map[str
 
 // BuildIndexMock 边生成EDB边清除临时数据，不依赖全局DB存储
 func (sp *SystemParameters) BuildIndexMock(invertedIndex map[string][]int, sortedKeywords []string) error {
-	sp.TreeHeight = int(math.Ceil(math.Log2(float64(len(invertedIndex)))))
-	sp.buildLocalTree(sortedKeywords)
+	// 构建 LocalTree，树高由关键词值域决定
+	if err := sp.buildLocalTree(sortedKeywords); err != nil {
+		return err
+	}
+	// 实验用的构建方式不记录文件，删除时不检查
+	sp.files = nil
 
 	// 1. 生成临时位图数据（不存入sp.DB，仅在内存中临时持有）
 	tempDB, err := sp.BuildDBMock(invertedIndex)
@@ -293,18 +345,14 @@ func (sp *SystemParameters) BuildIndexMock(invertedIndex map[string][]int, sorte
 		K_w := sp.PRF([]byte(tempCode))
 		ST_c, _ := sp.GenerateRandom()
 		ST_cplus1, _ := sp.GenerateRandom()
-		c := tempBitmap.c
-
-		// 临时转换计数器（局部变量，用完即销毁）
-		c_str := strconv.Itoa(c)
-		c_str_Array := []byte(c_str)
+		// 构建阶段每个节点只写入一个条目，计数器从 0 开始
+		c := 0
 
 		// 更新CT（仅保留必要的计数器和令牌，无冗余数据）
 		sp.CT[tempCode] = Counter{c: c, tokens: ST_cplus1}
 
 		// 生成加密密钥（临时big.Int，加密后立即丢弃）
-		skBytes := sp.H1(append(K_w, c_str_Array...))
-		sk := new(big.Int).SetBytes(skBytes) // 局部临时变量
+		sk := sp.genSK(K_w, c) // 局部临时变量
 
 		// 加密位图（使用临时DB中的bitmap，加密后不再引用）
 		encBitmap := sp.Enc(sk, tempBitmap.bs)
@@ -312,10 +360,7 @@ func (sp *SystemParameters) BuildIndexMock(invertedIndex map[string][]int, sorte
 		// 生成并存储到EDB
 		UT_cplus1 := sp.H1(append(K_w, ST_cplus1...))
 		C_ST, _ := XOR(UT_cplus1, ST_c)
-		sp.EDB[string(UT_cplus1)] = Data{
-			BigIntValue: encBitmap,
-			ByteValue:   C_ST,
-		}
+		sp.storeEntry(UT_cplus1, c, encBitmap, C_ST)
 
 		// 清除当前迭代的临时大对象（关键：主动释放big.Int引用）
 		sk = nil
@@ -327,23 +372,73 @@ func (sp *SystemParameters) BuildIndexMock(invertedIndex map[string][]int, sorte
 	return nil
 }
 
-// buildLocalTreeFromClusters 构建 LocalTree
-func (sp *SystemParameters) buildLocalTree(sortedKeywords []string) {
+// Domain 关键词的整数值域 [Min, Max]，叶子编码为 keyword-Min 的定长二进制，
+// 因此负数关键词（南纬、西经）与远大于关键词个数的关键词都能正确编码
+type Domain struct {
+	Min int64
+	Max int64
+}
+
+// DefaultDomain 返回 config.Range 对应的值域
+func DefaultDomain() *Domain {
+	return &Domain{Min: int64(config.Range[0]), Max: int64(config.Range[1])}
+}
+
+// Bits 叶子编码的位数，即 ceil(log2(Max-Min+1))，至少为 1
+func (d Domain) Bits() int {
+	return max(bits.Len64(uint64(d.Max-d.Min)), 1)
+}
+
+// Encode 将值域内的关键词编码为定长二进制串
+func (d Domain) Encode(v int64) (string, error) {
+	if v < d.Min || v > d.Max {
+		return "", fmt.Errorf("关键词 %d 超出值域 [%d, %d]", v, d.Min, d.Max)
+	}
+	return fmt.Sprintf("%0*b", d.Bits(), uint64(v-d.Min)), nil
+}
+
+// parseKeyword 将关键词解析为有符号整数
+func parseKeyword(keyword string) (int64, error) {
+	v, err := strconv.ParseInt(keyword, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("关键词 %q 不是整数: %v", keyword, err)
+	}
+	return v, nil
+}
+
+// buildLocalTree 按值域为每个关键词生成叶子编码，并据此设置树高
+func (sp *SystemParameters) buildLocalTree(sortedKeywords []string) error {
+	values := make([]int64, len(sortedKeywords))
+	for i, keyword := range sortedKeywords {
+		v, err := parseKeyword(keyword)
+		if err != nil {
+			return err
+		}
+		values[i] = v
+	}
+	if sp.Domain != nil {
+		sp.domain = *sp.Domain
+	} else if len(values) > 0 {
+		sp.domain = Domain{Min: slices.Min(values), Max: slices.Max(values)}
+	}
+	if sp.domain.Min > sp.domain.Max {
+		return fmt.Errorf("值域无效: [%d, %d]", sp.domain.Min, sp.domain.Max)
+	}
+	if sp.domain.Bits() > 62 {
+		return fmt.Errorf("值域 [%d, %d] 过大，叶子编码超过 62 位", sp.domain.Min, sp.domain.Max)
+	}
+	sp.TreeHeight = sp.domain.Bits() - 1
+
 	localTreeCode := make(map[string]string)
-	for _, keyword := range sortedKeywords {
-		intkey, _ := strconv.Atoi(keyword)
-		tempKey := fmt.Sprintf("%0*b", sp.TreeHeight+1, intkey) // 二进制表示
+	for i, keyword := range sortedKeywords {
+		tempKey, err := sp.domain.Encode(values[i]) // 二进制表示
+		if err != nil {
+			return err
+		}
 		localTreeCode[keyword] = tempKey
-		//fmt.Fprintf(file, "keyword == %s, localTreeCode: %s\n", keyword, tempKey)
-	}
-	//// 保存树的最终内容
-	//err = encoder.Encode(localTreeCode)
-	//if err != nil {
-	//	fmt.Println("Error encoding to JSON:", err)
-	//}
-	// 保存树和分区信息
-	//sp.LocalTree = localTree
+	}
 	sp.localTreeCode = localTreeCode
+	return nil
 }
 
 // TPath generates the set of node encodings (w) from the root to the keyword.
@@ -351,7 +446,14 @@ func (sp *SystemParameters) buildLocalTree(sortedKeywords []string) {
 func (sp *SystemParameters) TPath(keyword string) ([]string, error) {
 	code, exists := sp.localTreeCode[keyword]
 	if !exists {
-		return nil, fmt.Errorf("keyword %s not found in localTreeCode", keyword)
+		// 建立索引时不存在的关键词，只要落在值域内也能编码
+		v, err := parseKeyword(keyword)
+		if err != nil {
+			return nil, err
+		}
+		if code, err = sp.domain.Encode(v); err != nil {
+			return nil, err
+		}
 	}
 
 	var pt []string
@@ -388,142 +490,264 @@ func (sp *SystemParameters) UpdateBigInt(keyword string, bs *big.Int) error {
 	if err != nil {
 		return err
 	}
+	// 已存在的文件再次添加会向高位进位破坏位图
+	if err := sp.checkAdd(keyword, sp.files[PT[0]], bs); err != nil {
+		return err
+	}
 
 	// 2. 遍历 PT (for w ∈ PT)
 	for _, w := range PT {
-		// 4: Kw||K'w ← FK(w), (STc, c) ← CT[w]
-		// 简化 F_K(w) 的实现：使用 PRF 生成 Kw，使用 H1(Kw) 作为 K'w
-		// 在实际系统中，这应该是一个定义明确的 PRF 且输出长度足够分割。
-		Kw := sp.PRF([]byte(w))
-		// K'w 假设为 Kw 的一个哈希值，或者 F_K(w) 输出的后半部分
-		// 这里简化为 K'w = H2(Kw)
-		Kw_prime := sp.H2(Kw)
-
-		// (STc, c) ← CT[w]
-		info := getOrDefault(sp.CT, w, Counter{c: -1, tokens: []byte{}})
-		ST_c := info.tokens
-		c := info.c
-
-		// 5-7: if (STc, c) = ⊥ then c ← −1, STc ← {0, 1}λ end if
-		if c == -1 {
-			// c 已经设置为 -1
-			ST_c, _ = sp.GenerateRandom() // 生成随机值
-		}
-
-		// 8: STc+1 ← {0, 1}λ
-		ST_cplus1, _ := sp.GenerateRandom()
-		c_plus1 := c + 1
+		if _, err := sp.appendEntry(w, bs); err != nil {
+			return err
+		}
+	}
+	sp.addFiles(PT[0], bs)
 
-		// 9: CT[w] ← (STc+1, c + 1)
-		sp.CT[w] = Counter{tokens: ST_cplus1, c: c_plus1}
+	return nil
+}
 
-		// 将 int (c+1) 转换为 []byte
-		c_plus1_str := strconv.Itoa(c_plus1)
-		c_plus1_Array := []byte(c_plus1_str)
+// appendEntry 在节点 w 的令牌链末尾追加一个加密 bs 的条目并写入 EDB，返回条目的地址 UT
+func (sp *SystemParameters) appendEntry(w string, bs *big.Int) (string, error) {
+	// 4: Kw||K'w ← FK(w), (STc, c) ← CT[w]
+	// 简化 F_K(w) 的实现：使用 PRF 生成 Kw，使用 H1(Kw) 作为 K'w
+	// 在实际系统中，这应该是一个定义明确的 PRF 且输出长度足够分割。
+	// K'w 由 genSK 从 Kw 和系统密钥派生
+	// (STc, c) ← CT[w]
+	info := getOrDefault(sp.CT, w, Counter{c: -1, tokens: []byte{}})
+	Kw := sp.nodeKey(w, info.epoch)
+	ST_c := info.tokens
+	c := info.c
+
+	// 5-7: if (STc, c) = ⊥ then c ← −1, STc ← {0, 1}λ end if
+	if c == -1 {
+		// c 已经设置为 -1
+		ST_c, _ = sp.GenerateRandom() // 生成随机值
+	}
 
-		// 10: UTc+1 ← H1(Kw, STc+1)
-		UT_cplus1 := sp.H1(append(Kw, ST_cplus1...))
+	// 8: STc+1 ← {0, 1}λ
+	ST_cplus1, _ := sp.GenerateRandom()
+	c_plus1 := c + 1
 
-		// 11: CSTc ← H2(Kw, STc+1) ⊕ STc
-		// 注意：协议图中使用 H2(Kw, STc+1)，而 BuildIndex 中使用了 UTc+1 (即 H1(Kw, STc+1))。
-		// 为遵循图片算法，我们使用 H2。
-		H2_output := sp.H2(append(Kw, ST_cplus1...))
-		C_STc, err := XOR(H2_output, ST_c)
-		if err != nil {
-			return fmt.Errorf("XOR error for C_STc: %v", err)
-		}
+	// 9: CT[w] ← (STc+1, c + 1)
+	sp.CT[w] = Counter{tokens: ST_cplus1, c: c_plus1, epoch: info.epoch}
 
-		// 12: skc+1 ← H3(K'w, c + 1)
-		// 假设 H3 等于 H1
-		sk_cplus1_bytes := sp.H1(append(Kw_prime, c_plus1_Array...))
-		sk_cplus1 := new(big.Int).SetBytes(sk_cplus1_bytes)
+	// 10: UTc+1 ← H1(Kw, STc+1)
+	UT_cplus1 := sp.H1(append(Kw, ST_cplus1...))
 
-		// 13: ec+1 ← Enc(skc+1, bs, n)
-		e_cplus1 := sp.Enc(sk_cplus1, bs)
-		e_cplus1 = nil
-		// 14: Send (UTc+1,(ec+1, CSTc)) to the server. (即更新 EDB)
-		// Server: 17: Set EDB[UTc+1] ← (ec+1, CSTc)
-		sp.EDB[string(UT_cplus1)] = Data{
-			BigIntValue: e_cplus1, // ec+1
-			ByteValue:   C_STc,    // CSTc
-		}
+	// 11: CSTc ← H2(Kw, STc+1) ⊕ STc
+	// 注意：协议图中使用 H2(Kw, STc+1)，而 BuildIndex 中使用了 UTc+1 (即 H1(Kw, STc+1))。
+	// 为遵循图片算法，我们使用 H2。
+	H2_output := sp.H2(append(Kw, ST_cplus1...))
+	C_STc, err := XOR(H2_output, ST_c)
+	if err != nil {
+		return "", fmt.Errorf("XOR error for C_STc: %v", err)
 	}
 
-	return nil
+	// 12: skc+1 ← H3(K'w, c + 1)
+	sk_cplus1 := sp.genSK(Kw, c_plus1)
+
+	// 13: ec+1 ← Enc(skc+1, bs, n)
+	e_cplus1 := sp.Enc(sk_cplus1, bs)
+	// 14: Send (UTc+1,(ec+1, CSTc)) to the server. (即更新 EDB)
+	// Server: 17: Set EDB[UTc+1] ← (ec+1, CSTc)
+	sp.storeEntry(UT_cplus1, c_plus1, e_cplus1, C_STc)
+	return string(UT_cplus1), nil
 }
 
 // Update 更新FB_RSSE的索引，文档ID改为int类型
 func (sp *SystemParameters) Update(keyword string, bs int) error {
-	// 1. 获取路径上的所有编码 PT
+	return sp.UpdateBigInt(keyword, big.NewInt(int64(bs)))
+}
+
+// DeleteBigInt 后向安全删除：沿 TPath(keyword) 为每个节点追加一个加密 (n - bs) mod n 的条目，
+// Enc/Add 在模 n 下加法同态，节点链上的密文之和解密后被删除的位恰好抵消。
+// 删除条目与添加条目形式相同，服务器无法区分；解密结果中不再包含被删除的文件，
+// Consolidate 之后链上只剩合并后的位图，被删除文件的添加条目也从 EDB 中移除。
+// bs 中的文件须已添加到该关键词且尚未删除，否则减法会向高位借位破坏位图，
+// 因此客户端按记录拒绝删除从未添加或已删除的文件，计数器与 EDB 保持不变
+func (sp *SystemParameters) DeleteBigInt(keyword string, bs *big.Int) error {
+	if bs.Sign() < 0 || bs.Cmp(sp.n) >= 0 {
+		return fmt.Errorf("删除位图超出位图长度 %d", sp.BsLength)
+	}
 	PT, err := sp.TPath(keyword)
 	if err != nil {
 		return err
 	}
-
-	// 2. 遍历 PT (for w ∈ PT)
+	if err := sp.checkDelete(keyword, sp.files[PT[0]], bs); err != nil {
+		return err
+	}
+	negated := new(big.Int).Sub(sp.n, bs)
+	negated.Mod(negated, sp.n)
 	for _, w := range PT {
-		// 4: Kw||K'w ← FK(w), (STc, c) ← CT[w]
-		// 简化 F_K(w) 的实现：使用 PRF 生成 Kw，使用 H1(Kw) 作为 K'w
-		// 在实际系统中，这应该是一个定义明确的 PRF 且输出长度足够分割。
-		Kw := sp.PRF([]byte(w))
-		// K'w 假设为 Kw 的一个哈希值，或者 F_K(w) 输出的后半部分
-		// 这里简化为 K'w = H2(Kw)
-		Kw_prime := sp.H2(Kw)
-
-		// (STc, c) ← CT[w]
-		info := getOrDefault(sp.CT, w, Counter{c: -1, tokens: []byte{}})
-		ST_c := info.tokens
-		c := info.c
-
-		// 5-7: if (STc, c) = ⊥ then c ← −1, STc ← {0, 1}λ end if
-		if c == -1 {
-			// c 已经设置为 -1
-			ST_c, _ = sp.GenerateRandom() // 生成随机值
-		}
-
-		// 8: STc+1 ← {0, 1}λ
-		ST_cplus1, _ := sp.GenerateRandom()
-		c_plus1 := c + 1
+		if _, err := sp.appendEntry(w, negated); err != nil {
+			return err
+		}
+	}
+	sp.removeFiles(PT[0], bs)
+	return nil
+}
 
-		// 9: CT[w] ← (STc+1, c + 1)
-		sp.CT[w] = Counter{tokens: ST_cplus1, c: c_plus1}
+// Delete 从关键词中删除文件 docIDs，重复的文件 ID 只删除一次
+func (sp *SystemParameters) Delete(keyword string, docIDs []int) error {
+	bs := big.NewInt(0)
+	for _, id := range docIDs {
+		if id < 0 || id >= sp.BsLength {
+			return fmt.Errorf("文件 ID %d 超出位图长度 %d", id, sp.BsLength)
+		}
+		bs.SetBit(bs, id, 1)
+	}
+	return sp.DeleteBigInt(keyword, bs)
+}
 
-		// 将 int (c+1) 转换为 []byte
-		c_plus1_str := strconv.Itoa(c_plus1)
-		c_plus1_Array := []byte(c_plus1_str)
+// Update 批量更新中的一项：向关键词 Keyword 添加文件 DocIDs，Delete 为 true 时删除这些文件
+type Update struct {
+	Keyword string
+	DocIDs  []int
+	Delete  bool
+}
 
-		// 10: UTc+1 ← H1(Kw, STc+1)
-		UT_cplus1 := sp.H1(append(Kw, ST_cplus1...))
+// BatchUpdate 批量执行添加与删除：先按 TPath 将每一项的位图（删除为 (n - bs) mod n）在模 n 下累加到路径上的节点，
+// 再为每个受影响的节点只追加一个加密条目，链长度与加密次数都只随节点数增长。
+// 条目与 UpdateBigInt 一样写入 EDB，返回值为这些条目组成的单条服务器更新消息。
+// 任一项无效时返回错误，计数器与 EDB 保持不变
+func (sp *SystemParameters) BatchUpdate(updates []Update) (map[string]Data, error) {
+	sums := make(map[string]*big.Int)
+	// 按顺序模拟每一项对文件记录的修改，删除无效时整批拒绝
+	files := make(map[string]*big.Int)
+	for _, u := range updates {
+		bs := big.NewInt(0)
+		for _, id := range u.DocIDs {
+			if id < 0 || id >= sp.BsLength {
+				return nil, fmt.Errorf("文件 ID %d 超出位图长度 %d", id, sp.BsLength)
+			}
+			bs.SetBit(bs, id, 1)
+		}
+		PT, err := sp.TPath(u.Keyword)
+		if err != nil {
+			return nil, err
+		}
+		leaf := PT[0]
+		if _, exists := files[leaf]; !exists {
+			files[leaf] = new(big.Int).Set(getOrDefault(sp.files, leaf, big.NewInt(0)))
+		}
+		if u.Delete {
+			if err := sp.checkDelete(u.Keyword, files[leaf], bs); err != nil {
+				return nil, err
+			}
+			files[leaf].AndNot(files[leaf], bs)
+			bs.Sub(sp.n, bs)
+			bs.Mod(bs, sp.n)
+		} else {
+			if err := sp.checkAdd(u.Keyword, files[leaf], bs); err != nil {
+				return nil, err
+			}
+			files[leaf].Or(files[leaf], bs)
+		}
+		for _, w := range PT {
+			sums[w] = sp.Add(getOrDefault(sums, w, big.NewInt(0)), bs)
+		}
+	}
 
-		// 11: CSTc ← H2(Kw, STc+1) ⊕ STc
-		// 注意：协议图中使用 H2(Kw, STc+1)，而 BuildIndex 中使用了 UTc+1 (即 H1(Kw, STc+1))。
-		// 为遵循图片算法，我们使用 H2。
-		H2_output := sp.H2(append(Kw, ST_cplus1...))
-		C_STc, err := XOR(H2_output, ST_c)
+	// 按节点编码排序，保证计数器与条目的生成顺序确定
+	nodes := make([]string, 0, len(sums))
+	for w := range sums {
+		nodes = append(nodes, w)
+	}
+	sort.Strings(nodes)
+	entries := make(map[string]Data, len(nodes))
+	for _, w := range nodes {
+		UT, err := sp.appendEntry(w, sums[w])
 		if err != nil {
-			return fmt.Errorf("XOR error for C_STc: %v", err)
+			return nil, err
+		}
+		entries[UT] = sp.EDB[UT]
+	}
+	if sp.files != nil {
+		for leaf, bs := range files {
+			sp.files[leaf] = bs
 		}
+	}
+	return entries, nil
+}
 
-		// 12: skc+1 ← H3(K'w, c + 1)
-		// 假设 H3 等于 H1
-		sk_cplus1_bytes := sp.H1(append(Kw_prime, c_plus1_Array...))
-		sk_cplus1 := new(big.Int).SetBytes(sk_cplus1_bytes)
+// recordFiles 由倒排索引建立客户端的文件记录
+func (sp *SystemParameters) recordFiles(invertedIndex map[string][]int) error {
+	sp.files = make(map[string]*big.Int, len(invertedIndex))
+	for keyword, docIDs := range invertedIndex {
+		PT, err := sp.TPath(keyword)
+		if err != nil {
+			return err
+		}
+		bs := big.NewInt(0)
+		for _, id := range docIDs {
+			bs.SetBit(bs, id, 1)
+		}
+		sp.addFiles(PT[0], bs)
+	}
+	return nil
+}
+
+// addFiles 在文件记录中加入叶子节点 leaf 新添加的文件
+func (sp *SystemParameters) addFiles(leaf string, bs *big.Int) {
+	if sp.files == nil {
+		return
+	}
+	files := getOrDefault(sp.files, leaf, big.NewInt(0))
+	sp.files[leaf] = new(big.Int).Or(files, bs)
+}
+
+// removeFiles 从文件记录中去掉叶子节点 leaf 已删除的文件
+func (sp *SystemParameters) removeFiles(leaf string, bs *big.Int) {
+	if sp.files == nil {
+		return
+	}
+	if files, exists := sp.files[leaf]; exists {
+		sp.files[leaf] = new(big.Int).AndNot(files, bs)
+	}
+}
 
-		// 13: ec+1 ← Enc(skc+1, bs, n)
-		bigIntBs := new(big.Int).SetInt64(int64(bs))
-		e_cplus1 := sp.Enc(sk_cplus1, bigIntBs)
+// checkDelete 检查要删除的文件 bs 都在关键词当前的文件 files 中，未记录文件时不检查
+func (sp *SystemParameters) checkDelete(keyword string, files *big.Int, bs *big.Int) error {
+	if sp.files == nil {
+		return nil
+	}
+	if files == nil {
+		files = big.NewInt(0)
+	}
+	missing := []int{}
+	for id := 0; id < bs.BitLen(); id++ {
+		if bs.Bit(id) == 1 && files.Bit(id) == 0 {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("关键词 %s 中不存在要删除的文件（从未添加或已删除）: %v", keyword, missing)
+	}
+	return nil
+}
 
-		// 14: Send (UTc+1,(ec+1, CSTc)) to the server. (即更新 EDB)
-		// Server: 17: Set EDB[UTc+1] ← (ec+1, CSTc)
-		sp.EDB[string(UT_cplus1)] = Data{
-			BigIntValue: e_cplus1, // ec+1
-			ByteValue:   C_STc,    // CSTc
+// checkAdd 检查要添加的文件 bs 都不在关键词当前的文件 files 中，未记录文件时不检查
+func (sp *SystemParameters) checkAdd(keyword string, files *big.Int, bs *big.Int) error {
+	if sp.files == nil || files == nil {
+		return nil
+	}
+	existing := []int{}
+	for id := 0; id < bs.BitLen(); id++ {
+		if bs.Bit(id) == 1 && files.Bit(id) == 1 {
+			existing = append(existing, id)
 		}
 	}
+	if len(existing) > 0 {
+		return fmt.Errorf("关键词 %s 中已存在要添加的文件: %v", keyword, existing)
+	}
 	return nil
 }
+
 func (sp *SystemParameters) GenToken(queryRange [2]string, sortedKeywords []string) ([][]byte, [][]byte, []int, error) {
-	targetValue, _ := sp.getBRC(queryRange, sortedKeywords)
+	targetValue, err := sp.getBRC(queryRange, sortedKeywords)
+	if err != nil {
+		return nil, nil, nil, err
+	}
 	//fmt.Println("BRC:", targetValue)
 	var K_w_set [][]byte
 	var ST_set [][]byte
@@ -531,11 +755,12 @@ func (sp *SystemParameters) GenToken(queryRange [2]string, sortedKeywords []stri
 	for _, tempCode := range targetValue {
 		//fmt.Println("Dealing with:", tempCode)
 		//加密索引
-		K_w := sp.PRF([]byte(tempCode))
 		info := getOrDefault(sp.CT, tempCode, Counter{c: -1, tokens: []byte{}})
 		if info.c == -1 {
-			return nil, nil, []int{-1}, nil
+			// 值域稀疏时覆盖中的节点下可能没有任何关键词
+			continue
 		}
+		K_w := sp.nodeKey(tempCode, info.epoch)
 		K_w_set = append(K_w_set, K_w)
 		ST_set = append(ST_set, info.tokens)
 		c_set = append(c_set, info.c)
@@ -543,49 +768,121 @@ func (sp *SystemParameters) GenToken(queryRange [2]string, sortedKeywords []stri
 
 	return K_w_set, ST_set, c_set, nil
 }
-func (sp *SystemParameters) ServerSearch(K_w_set [][]byte, ST_set [][]byte, c_set []int) (*big.Int, error) {
-	// 初始化 Sum 为 0
-	var Sum = big.NewInt(0)
-	// 用于存储每个 Sum_e
-	var Sum_e = big.NewInt(0)
+
+// NodeResult 服务器对单个 BRC 节点的搜索结果
+type NodeResult struct {
+	Sum_e *big.Int // 节点链上所有密文之和
+	UTs   [][]byte // 链上访问到的条目地址，合并时由服务器删除
+}
+
+// ServerSearchNodes 沿每个节点的令牌链累加密文，不修改 EDB
+func (sp *SystemParameters) ServerSearchNodes(K_w_set [][]byte, ST_set [][]byte, c_set []int) ([]NodeResult, error) {
+	nodes := make([]NodeResult, 0, len(K_w_set))
 	for index, K_w_i := range K_w_set {
-		// 初始化 Sum_e 为 0
-		Sum_e.SetInt64(0)
+		node := NodeResult{Sum_e: big.NewInt(0)}
 		// 从 c_set 的最后一个元素开始遍历
 		ST_j := ST_set[index]
 		for j := c_set[index]; j >= 0; j-- {
 			UT_j := sp.H1(append(K_w_i, ST_j...))
-			// 以十六进制格式打印所有三个字节数组，在一行中
-			//fmt.Printf("K_w_i in hex: %x, ST_j in hex: %x, UT_j in hex: %x\n", K_w_i, ST_j, UT_j)
-			data := sp.EDB[string(UT_j)]
-			if data.ByteValue == nil {
+			data, exists := sp.EDB[string(UT_j)]
+			if !exists {
 				break
 			}
-			Sum_e = sp.Add(Sum_e, data.BigIntValue)
-			delete(sp.EDB, string(UT_j))
+			node.Sum_e = sp.Add(node.Sum_e, data.BigIntValue)
+			node.UTs = append(node.UTs, UT_j)
 			ST_j, _ = XOR(UT_j, data.ByteValue)
-			//fmt.Printf("ST_j in hex: %x, data.ByteValue in hex: %x\n", ST_j, data.ByteValue)
 		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
 
-		// 将当前 Sum_e 加到 Sum 中
-		Sum = sp.Add(Sum, Sum_e)
+// ServerSearch 返回所有节点密文之和，搜索不会删除 EDB 中的条目，重复查询结果一致
+func (sp *SystemParameters) ServerSearch(K_w_set [][]byte, ST_set [][]byte, c_set []int) (*big.Int, error) {
+	nodes, err := sp.ServerSearchNodes(K_w_set, ST_set, c_set)
+	if err != nil {
+		return nil, err
+	}
+	var Sum = big.NewInt(0)
+	for _, node := range nodes {
+		Sum = sp.Add(Sum, node.Sum_e)
 	}
-	// 返回最终的 Sum
 	return Sum, nil
 }
+
+// ConsolidateMessage Consolidate 生成的服务器更新消息：旧链上需要删除的条目与合并后写入的条目
+type ConsolidateMessage struct {
+	Deletes [][]byte        // 需要删除的旧条目地址 UT
+	Entries map[string]Data // 合并后的条目
+}
+
+// Consolidate 客户端解密每个节点的搜索结果，为其重新上传一个合并后的条目：
+// 新的 ST、计数器重置为 0、K_w 进入下一轮，服务器删除链上的旧条目，链长度因此保持为 1。
+// 合并后的条目与 BatchUpdate 一样写入 EDB，旧条目由服务器收到返回的消息后调用 ApplyConsolidate 删除。
+// 搜索之后若节点又被更新（计数器与令牌中的不一致），该节点不合并
+func (sp *SystemParameters) Consolidate(queryRange [2]string, sortedKeywords []string, K_w_set [][]byte, c_set []int, nodes []NodeResult) (*ConsolidateMessage, error) {
+	if len(nodes) != len(K_w_set) || len(c_set) != len(K_w_set) {
+		return nil, fmt.Errorf("合并参数长度不一致: %d 个令牌, %d 个计数器, %d 个结果", len(K_w_set), len(c_set), len(nodes))
+	}
+	codes, err := sp.getBRC(queryRange, sortedKeywords)
+	if err != nil {
+		return nil, err
+	}
+	msg := &ConsolidateMessage{Entries: make(map[string]Data)}
+	for _, code := range codes {
+		info, exists := sp.CT[code]
+		if !exists {
+			continue
+		}
+		K_w := sp.nodeKey(code, info.epoch)
+		i := 0
+		for i < len(K_w_set) && !bytes.Equal(K_w_set[i], K_w) {
+			i++
+		}
+		if i == len(K_w_set) || c_set[i] != info.c {
+			continue
+		}
+
+		// 客户端：解密节点位图
+		Sum_sk := big.NewInt(0)
+		for j := c_set[i]; j >= 0; j-- {
+			Sum_sk = sp.Add(Sum_sk, sp.genSK(K_w, j))
+		}
+		bs := sp.Dec(Sum_sk, nodes[i].Sum_e)
+
+		// 客户端：以新的 K_w 和 ST 生成唯一条目
+		epoch := info.epoch + 1
+		K_w_new := sp.nodeKey(code, epoch)
+		ST_c, _ := sp.GenerateRandom()
+		ST_cplus1, _ := sp.GenerateRandom()
+		UT_cplus1 := sp.H1(append(K_w_new, ST_cplus1...))
+		C_ST, _ := XOR(UT_cplus1, ST_c)
+		sp.CT[code] = Counter{tokens: ST_cplus1, c: 0, epoch: epoch}
+
+		// 服务器：删除旧链并写入合并后的条目
+		msg.Deletes = append(msg.Deletes, nodes[i].UTs...)
+		sp.storeEntry(UT_cplus1, 0, sp.Enc(sp.genSK(K_w_new, 0), bs), C_ST)
+		msg.Entries[string(UT_cplus1)] = sp.EDB[string(UT_cplus1)]
+	}
+	return msg, nil
+}
+
+// ApplyConsolidate 服务器：删除消息中的旧条目并写入合并后的条目
+func (sp *SystemParameters) ApplyConsolidate(msg *ConsolidateMessage) {
+	for _, UT := range msg.Deletes {
+		delete(sp.EDB, string(UT))
+	}
+	for UT, data := range msg.Entries {
+		sp.EDB[UT] = data
+	}
+}
+
 func (sp *SystemParameters) LocalParse(K_w_set [][]byte, c_set []int, Sum *big.Int) (*big.Int, error) {
 	// 用于存储每个 Sum_e
 	var Sum_sk = big.NewInt(0)
-	var sk_i = big.NewInt(0)
 	for i, K_w_i := range K_w_set {
 		for j := c_set[i]; j >= 0; j-- {
-			// 将 int 转换为 string
-			c_str := strconv.Itoa(j)
-			// 将 string 转换为 []byte
-			c_str_Array := []byte(c_str)
-			sk_i_bytes := sp.H1(append(K_w_i, c_str_Array...))
-			sk_i.SetBytes(sk_i_bytes)
-			Sum_sk = sp.Add(Sum_sk, sk_i)
+			Sum_sk = sp.Add(Sum_sk, sp.genSK(K_w_i, j))
 		}
 		// 打印每个 Sum_sk 对应的位图
 		//PrintBitmap(Sum_sk, 50) // 打印 Sum_sk 的最低 30 位
@@ -594,42 +891,53 @@ func (sp *SystemParameters) LocalParse(K_w_set [][]byte, c_set []int, Sum *big.I
 	return sp.Dec(Sum_sk, Sum), nil
 }
 
-// searchTree 在本地树中查找关键词的位置
-func (sp *SystemParameters) searchTree(sortedKeywords []string, queryValue string, findLarger bool) (string, error) {
-	queryValueIndex := indexOf(sortedKeywords, queryValue)
-	if queryValueIndex != -1 {
-		return sp.localTreeCode[sortedKeywords[queryValueIndex]], nil
+// getBRC 将查询范围收缩到范围内的首个与末个关键词，返回覆盖二者叶子编码区间的前缀编码
+// sortedKeywords 需按数值升序排列，范围内没有关键词时返回空集合
+func (sp *SystemParameters) getBRC(queryRange [2]string, sortedKeywords []string) ([]string, error) {
+	left, err := parseKeyword(queryRange[0])
+	if err != nil {
+		return nil, err
+	}
+	right, err := parseKeyword(queryRange[1])
+	if err != nil {
+		return nil, err
 	}
-	queryRangeInt, _ := strconv.Atoi(queryValue)
-	// 将 localCluster[0] 转换为 []int
-	sortedKeywordsInt := make([]int, len(sortedKeywords))
-	for i, v := range sortedKeywords {
-		sortedKeywordsInt[i], _ = strconv.Atoi(v)
+	values := make([]int64, len(sortedKeywords))
+	for i, keyword := range sortedKeywords {
+		if values[i], err = parseKeyword(keyword); err != nil {
+			return nil, err
+		}
 	}
-	// 找到比 queryRange[0] 大且差距最小的值
-	tempIndex := binarySearchClosest(sortedKeywordsInt, queryRangeInt, findLarger)
-
-	return sortedKeywords[tempIndex], nil
-}
-func (sp *SystemParameters) getBRC(queryRange [2]string, sortedKeywords []string) ([]string, error) {
-	newQueryLeft, _ := sp.searchTree(sortedKeywords, queryRange[0], true)
-	newQueryRight, _ := sp.searchTree(sortedKeywords, queryRange[1], false)
+	i := sort.Search(len(values), func(k int) bool { return values[k] >= left })
+	j := sort.Search(len(values), func(k int) bool { return values[k] > right }) - 1
+	if i > j {
+		return []string{}, nil
+	}
+	newQueryLeft := sp.localTreeCode[sortedKeywords[i]]
+	newQueryRight := sp.localTreeCode[sortedKeywords[j]]
 	if newQueryLeft == newQueryRight {
 		return []string{newQueryLeft}, nil
 	}
-	a, _ := strconv.ParseInt(newQueryLeft, 2, 64)
-	b, _ := strconv.ParseInt(newQueryRight, 2, 64)
-	rangeInt := make([]int, b-a+1)
-	for i := range rangeInt {
-		// 将 int64 转换为 int，确保类型匹配
-		rangeInt[i] = int(a + int64(i))
-	}
-	cover, err := sp.preCover(rangeInt)
-	if err != nil {
-		return nil, err
+	a, _ := strconv.ParseUint(newQueryLeft, 2, 64)
+	b, _ := strconv.ParseUint(newQueryRight, 2, 64)
+	return sp.rangeCover(a, b), nil
+}
+
+// rangeCover 直接计算叶子区间 [a, b] 的最小前缀覆盖，结果与 preCover 相同（顺序可能不同），
+// 但不需要枚举区间内的每个叶子，耗时只与树高有关
+func (sp *SystemParameters) rangeCover(a, b uint64) []string {
+	height := sp.TreeHeight + 1
+	cover := []string{}
+	for a <= b {
+		// 从 a 开始能放下的最大对齐子树，根节点不参与覆盖
+		k := min(bits.TrailingZeros64(a), height-1)
+		for a+(1<<k)-1 > b {
+			k--
+		}
+		cover = append(cover, fmt.Sprintf("%0*b", height-k, a>>k)+strings.Repeat("*", k))
+		a += 1 << k
 	}
-	// 最后返回的 resultSet
-	return cover, nil
+	return cover
 }
 
 // GetBPCValueMap 获取BPC值的映射
@@ -768,21 +1076,25 @@ func (sp *SystemParameters) preCover(R []int) ([]string, error) {
 
 // Enc 加密方法
 func (sp *SystemParameters) Enc(sk, m *big.Int) *big.Int {
+	if sp.PlaintextAblation {
+		return new(big.Int).Set(m)
+	}
 	// Enc(sk, m) = (sk + m) % n
 	result := new(big.Int).Add(sk, m)
 	result.Mod(result, sp.n)
-	//return result
-	return m
+	return result
 }
 
 // Dec 解密方法
 func (sp *SystemParameters) Dec(sk, e *big.Int) *big.Int {
+	if sp.PlaintextAblation {
+		return new(big.Int).Set(e)
+	}
 	// Dec(sk, e) = (e - sk + n) % n
 	result := new(big.Int).Sub(e, sk)
 	result.Add(result, sp.n)
 	result.Mod(result, sp.n)
-	//return result
-	return e
+	return result
 }
 
 // Add 加法同态方法
@@ -836,36 +1148,6 @@ func indexOf(slice []string, value string) int {
 	}
 	return -1 // 未找到返回 -1
 }
-func binarySearchClosest(slice []int, value int, findLarger bool) int {
-	low, high := 0, len(slice)-1
-	closest := -1
-
-	for low <= high {
-		mid := (low + high) / 2
-		if slice[mid] == value {
-			return mid
-		}
-		if findLarger {
-			if slice[mid] > value {
-				closest = mid
-				high = mid - 1
-			} else {
-				low = mid + 1
-			}
-		} else {
-			if slice[mid] < value {
-				closest = mid
-				low = mid + 1
-			} else {
-				high = mid - 1
-			}
-		}
-	}
-	if closest < 0 {
-		return 0
-	}
-	return closest
-}
 
 // getOrDefault 使用泛型处理任意类型的 map
 func getOrDefault[K comparable, V any](m map[K]V, key K, defaultValue V) V {
@@ -880,9 +1162,12 @@ func getOrDefault[K comparable, V any](m map[K]V, key K, defaultValue V) V {
 
 // BuildIndex 构建倒排索引
 func (sp *SystemParameters) BuildIndex_dynamic(invertedIndex map[string][]int, sortedKeywords []string) error {
-	sp.TreeHeight = int(math.Ceil(math.Log2(float64(len(invertedIndex)))))
-	// 构建 LocalTree
-	sp.buildLocalTree(sortedKeywords)
+	// 构建 LocalTree，树高由关键词值域决定
+	if err := sp.buildLocalTree(sortedKeywords); err != nil {
+		return err
+	}
+	// 实验用的构建方式不记录文件，删除时不检查
+	sp.files = nil
 	// 创建一个大整数表示位图
 	for keyword, docIDs := range invertedIndex {
 		code := sp.localTreeCode[keyword]
@@ -919,15 +1204,8 @@ func (sp *SystemParameters) BuildIndex_dynamic(invertedIndex map[string][]int, s
 				// 将对应的位设置为 1，使用 big.Int 的 SetBit 方法
 				bitmap.SetBit(bitmap, docID+1, 1)
 			}
-			// 将 int 转换为 string
-			c_str := strconv.Itoa(info.c + 1)
-			// 将 string 转换为 []byte
-			c_str_Array := []byte(c_str)
-			encBitmap := sp.Enc(new(big.Int).SetBytes(sp.H1(append(K_w, c_str_Array...))), bitmap)
-			sp.EDB[string(UT_cplus1)] = Data{
-				BigIntValue: encBitmap,
-				ByteValue:   C_ST,
-			}
+			encBitmap := sp.Enc(sp.genSK(K_w, info.c+1), bitmap)
+			sp.storeEntry(UT_cplus1, info.c+1, encBitmap, C_ST)
 
 		}
 	}
@@ -968,3 +1246,126 @@ func PrintLowestBits(encBitmap *big.Int, maxBits int) {
 	// 打印二进制字符串的最低若干位
 	fmt.Printf("info.bs (lowest %d bits): %s\n", maxBits, bits)
 }
+
+// Save 将 EDB 按 storage 格式写出，服务器重启后可通过 Load 恢复而无需客户端重新 BuildIndex
+func (sp *SystemParameters) Save(w io.Writer) error {
+	sw, err := storage.NewWriter(w, storage.Header{
+		Scheme: storage.SchemeFBRSSE,
+		Param:  uint64(sp.BsLength),
+		Count:  uint64(len(sp.EDB)),
+	})
+	if err != nil {
+		return err
+	}
+	for UT, data := range sp.EDB {
+		// 最后一个字段为条目的 MAC，未启用可验证模式时为空
+		for _, field := range [][]byte{[]byte(UT), data.BigIntValue.Bytes(), data.ByteValue, data.Tag} {
+			if err := sw.WriteField(field); err != nil {
+				return err
+			}
+		}
+	}
+	return sw.Flush()
+}
+
+// Load 从 Save 写出的数据中恢复 EDB，文件中的 BsLength 与当前参数不一致时返回错误
+func (sp *SystemParameters) Load(r io.Reader) error {
+	sr, err := storage.NewReader(r, storage.SchemeFBRSSE)
+	if err != nil {
+		return err
+	}
+	if sr.Header.Param != uint64(sp.BsLength) {
+		return fmt.Errorf("EDB 参数不匹配: 文件中 BsLength=%d，当前 BsLength=%d", sr.Header.Param, sp.BsLength)
+	}
+	EDB := make(map[string]Data, sr.Header.Count)
+	for i := uint64(0); i < sr.Header.Count; i++ {
+		var fields [4][]byte
+		for j := range fields {
+			if fields[j], err = sr.ReadField(); err != nil {
+				return fmt.Errorf("读取第 %d 个条目失败: %v", i, err)
+			}
+		}
+		data := Data{
+			BigIntValue: new(big.Int).SetBytes(fields[1]),
+			ByteValue:   fields[2],
+		}
+		if len(fields[3]) > 0 {
+			data.Tag = fields[3]
+		}
+		EDB[string(fields[0])] = data
+	}
+	sp.EDB = EDB
+	return nil
+}
+
+// counterState Counter 的可序列化形式
+type counterState struct {
+	Tokens []byte
+	C      int
+	Epoch  int
+}
+
+// clientState 客户端状态中需要持久化的部分，H1/H2 为固定的 SHA-256，导入时重新生成
+type clientState struct {
+	K                 []byte
+	CT                map[string]counterState
+	BsLength          int
+	LocalTree         map[string][]int64
+	LocalTreeCode     map[string]string
+	TreeHeight        int
+	Domain            Domain
+	PlaintextAblation bool
+	Verifiable        bool
+	Files             map[string]*big.Int
+}
+
+// ExportClient 将客户端状态（密钥、计数器、本地树）用口令派生的密钥加密并认证后写出，
+// 不包含 EDB，EDB 由服务器通过 Save/Load 持久化
+func (sp *SystemParameters) ExportClient(w io.Writer, password []byte) error {
+	state := clientState{
+		K:                 sp.K,
+		CT:                make(map[string]counterState, len(sp.CT)),
+		BsLength:          sp.BsLength,
+		LocalTree:         sp.LocalTree,
+		LocalTreeCode:     sp.localTreeCode,
+		TreeHeight:        sp.TreeHeight,
+		Domain:            sp.domain,
+		PlaintextAblation: sp.PlaintextAblation,
+		Verifiable:        sp.Verifiable,
+		Files:             sp.files,
+	}
+	for code, info := range sp.CT {
+		state.CT[code] = counterState{Tokens: info.tokens, C: info.c, Epoch: info.epoch}
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(state); err != nil {
+		return fmt.Errorf("序列化客户端状态失败: %v", err)
+	}
+	return storage.Seal(w, password, storage.SchemeFBRSSE, buf.Bytes())
+}
+
+// ImportClient 读取 ExportClient 写出的客户端状态，返回的系统参数 EDB 为空，
+// 口令错误或数据被篡改时返回错误
+func ImportClient(r io.Reader, password []byte) (*SystemParameters, error) {
+	plaintext, err := storage.Open(r, password, storage.SchemeFBRSSE)
+	if err != nil {
+		return nil, err
+	}
+	var state clientState
+	if err := gob.NewDecoder(bytes.NewReader(plaintext)).Decode(&state); err != nil {
+		return nil, fmt.Errorf("解析客户端状态失败: %v", err)
+	}
+	sp := Setup(state.BsLength)
+	sp.K = state.K
+	sp.LocalTree = state.LocalTree
+	sp.localTreeCode = state.LocalTreeCode
+	sp.TreeHeight = state.TreeHeight
+	sp.domain = state.Domain
+	sp.PlaintextAblation = state.PlaintextAblation
+	sp.Verifiable = state.Verifiable
+	sp.files = state.Files
+	for code, info := range state.CT {
+		sp.CT[code] = Counter{tokens: info.Tokens, c: info.C, epoch: info.Epoch}
+	}
+	return sp, nil
+}
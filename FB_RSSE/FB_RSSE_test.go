@@ -3,9 +3,12 @@ package FB_RSSE
 import (
 	"EfficientAndLowStroageSSE/config"
 	"bufio"
+	"bytes"
+	"errors"
 	"fmt"
 	"math/big"
 	"os"
+	"reflect"
 	"sort"
 	"strconv"
 	"strings"
@@ -318,3 +321,791 @@ func sortKeywords(invertedIndex map[string][]int) []string {
 	//log.Printf("min: %s, max: %s", keywords[0], keywords[len(invertedIndex)-1])
 	return keywords
 }
+
+// bitmapIDs 返回位图中为 1 的位
+func bitmapIDs(bs *big.Int) []int {
+	ids := []int{}
+	for i := 0; i < bs.BitLen(); i++ {
+		if bs.Bit(i) == 1 {
+			ids = append(ids, i)
+		}
+	}
+	return ids
+}
+
+// searchIDs 完成一次 GenToken、ServerSearch、LocalParse 并返回文件 ID
+func searchIDs(t *testing.T, sp *SystemParameters, queryRange [2]string, sortedKeywords []string) []int {
+	t.Helper()
+	K_w_set, ST_set, c_set, err := sp.GenToken(queryRange, sortedKeywords)
+	if err != nil {
+		t.Fatalf("GenToken returned an error: %v", err)
+	}
+	Sum, err := sp.ServerSearch(K_w_set, ST_set, c_set)
+	if err != nil {
+		t.Fatalf("ServerSearch returned an error: %v", err)
+	}
+	bs, err := sp.LocalParse(K_w_set, c_set, Sum)
+	if err != nil {
+		t.Fatalf("LocalParse returned an error: %v", err)
+	}
+	return bitmapIDs(bs)
+}
+
+// TestEnc_additive Enc 真正加密，且密文之和解密后等于明文之和
+func TestEnc_additive(t *testing.T) {
+	sp := Setup(64)
+	m1, m2 := big.NewInt(0b1010), big.NewInt(0b0101)
+	sk1, sk2 := sp.genSK([]byte("K_w"), 0), sp.genSK([]byte("K_w"), 1)
+
+	e1, e2 := sp.Enc(sk1, m1), sp.Enc(sk2, m2)
+	if e1.Cmp(m1) == 0 || e2.Cmp(m2) == 0 {
+		t.Fatalf("Enc returned the plaintext")
+	}
+	sum := sp.Dec(sp.Add(sk1, sk2), sp.Add(e1, e2))
+	if sum.Cmp(big.NewInt(0b1111)) != 0 {
+		t.Errorf("Dec(Add) mismatch: expected %b, got %b", 0b1111, sum)
+	}
+
+	ablation := SetupPlaintextAblation(64)
+	if e := ablation.Enc(sk1, m1); e.Cmp(m1) != 0 {
+		t.Errorf("plaintext ablation Enc mismatch: expected %v, got %v", m1, e)
+	}
+}
+
+// TestSearch_encrypted 加密模式下 EDB 中不出现明文位图，且搜索与更新结果正确
+func TestSearch_encrypted(t *testing.T) {
+	invertedIndex := map[string][]int{
+		"1": {1, 3},
+		"2": {4, 2, 5},
+		"3": {6, 7},
+		"4": {8, 9, 10, 11},
+		"5": {12, 13, 14, 15, 16},
+	}
+	sortedKeywords := sortKeywords(invertedIndex)
+
+	for _, sp := range []*SystemParameters{Setup(64), SetupPlaintextAblation(64)} {
+		if err := sp.BuildIndex(invertedIndex, sortedKeywords); err != nil {
+			t.Fatalf("BuildIndex returned an error: %v", err)
+		}
+		plaintexts := map[string]bool{}
+		for _, info := range sp.DB {
+			plaintexts[info.bs.String()] = true
+		}
+		for _, data := range sp.EDB {
+			if plaintexts[data.BigIntValue.String()] != sp.PlaintextAblation {
+				t.Errorf("PlaintextAblation=%v: EDB entry %v stored in the wrong mode", sp.PlaintextAblation, data.BigIntValue)
+			}
+		}
+
+		if err := sp.UpdateBigInt("3", new(big.Int).SetBit(big.NewInt(0), 20, 1)); err != nil {
+			t.Fatalf("UpdateBigInt returned an error: %v", err)
+		}
+		actual := searchIDs(t, sp, [2]string{"3", "3"}, sortedKeywords)
+		expected := []int{6, 7, 20}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("PlaintextAblation=%v: search result mismatch: expected %v, got %v", sp.PlaintextAblation, expected, actual)
+		}
+	}
+}
+
+// TestConsolidate 搜索不破坏 EDB，合并后链长度为 1 且结果不变
+func TestConsolidate(t *testing.T) {
+	invertedIndex := map[string][]int{
+		"1": {1, 3},
+		"2": {4, 2, 5},
+		"3": {6, 7},
+		"4": {8, 9, 10, 11},
+		"5": {12, 13, 14, 15, 16},
+	}
+	sortedKeywords := sortKeywords(invertedIndex)
+	sp := Setup(64)
+	if err := sp.BuildIndex(invertedIndex, sortedKeywords); err != nil {
+		t.Fatalf("BuildIndex returned an error: %v", err)
+	}
+	for _, id := range []int{20, 21, 22} {
+		if err := sp.UpdateBigInt("3", new(big.Int).SetBit(big.NewInt(0), id, 1)); err != nil {
+			t.Fatalf("UpdateBigInt returned an error: %v", err)
+		}
+	}
+
+	queryRange := [2]string{"2", "3"}
+	expected := []int{2, 4, 5, 6, 7, 20, 21, 22}
+	for i := 0; i < 2; i++ {
+		if actual := searchIDs(t, sp, queryRange, sortedKeywords); !reflect.DeepEqual(actual, expected) {
+			t.Fatalf("search %d mismatch: expected %v, got %v", i, expected, actual)
+		}
+	}
+
+	K_w_set, ST_set, c_set, err := sp.GenToken(queryRange, sortedKeywords)
+	if err != nil {
+		t.Fatalf("GenToken returned an error: %v", err)
+	}
+	nodes, err := sp.ServerSearchNodes(K_w_set, ST_set, c_set)
+	if err != nil {
+		t.Fatalf("ServerSearchNodes returned an error: %v", err)
+	}
+	before := len(sp.EDB)
+	msg, err := sp.Consolidate(queryRange, sortedKeywords, K_w_set, c_set, nodes)
+	if err != nil {
+		t.Fatalf("Consolidate returned an error: %v", err)
+	}
+	// Consolidate 只生成消息，旧条目由服务器删除
+	deletes := 0
+	for _, node := range nodes {
+		deletes += len(node.UTs)
+	}
+	if len(msg.Deletes) != deletes || len(sp.EDB) != before+len(msg.Entries) {
+		t.Errorf("Consolidate message mismatch: %d deletes (expected %d), EDB size %d (expected %d)", len(msg.Deletes), deletes, len(sp.EDB), before+len(msg.Entries))
+	}
+	sp.ApplyConsolidate(msg)
+	removed := 0
+	for _, node := range nodes {
+		removed += len(node.UTs) - 1
+	}
+	if removed == 0 || len(sp.EDB) != before-removed {
+		t.Errorf("EDB size mismatch after Consolidate: before %d, removed %d, got %d", before, removed, len(sp.EDB))
+	}
+
+	_, _, c_set, _ = sp.GenToken(queryRange, sortedKeywords)
+	for _, c := range c_set {
+		if c != 0 {
+			t.Errorf("counter not reset after Consolidate: %v", c_set)
+		}
+	}
+	if actual := searchIDs(t, sp, queryRange, sortedKeywords); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("search after Consolidate mismatch: expected %v, got %v", expected, actual)
+	}
+
+	if err := sp.UpdateBigInt("2", new(big.Int).SetBit(big.NewInt(0), 30, 1)); err != nil {
+		t.Fatalf("UpdateBigInt returned an error: %v", err)
+	}
+	expected = []int{2, 4, 5, 6, 7, 20, 21, 22, 30}
+	if actual := searchIDs(t, sp, queryRange, sortedKeywords); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("search after Consolidate and Update mismatch: expected %v, got %v", expected, actual)
+	}
+}
+
+// TestDelete 删除后再搜索，被删除的文件不会出现在 LocalParse 的结果中，合并后结果不变且可以重新添加
+func TestDelete(t *testing.T) {
+	invertedIndex := map[string][]int{
+		"1": {1, 3},
+		"2": {4, 2, 5},
+		"3": {6, 7},
+		"4": {8, 9, 10, 11},
+		"5": {12, 13, 14, 15, 16},
+	}
+	sortedKeywords := sortKeywords(invertedIndex)
+	deleted := map[int]bool{6: true, 9: true, 20: true}
+
+	for _, sp := range []*SystemParameters{Setup(64), SetupPlaintextAblation(64)} {
+		if err := sp.BuildIndex(invertedIndex, sortedKeywords); err != nil {
+			t.Fatalf("BuildIndex returned an error: %v", err)
+		}
+		if err := sp.UpdateBigInt("3", new(big.Int).SetBit(big.NewInt(0), 20, 1)); err != nil {
+			t.Fatalf("UpdateBigInt returned an error: %v", err)
+		}
+		before := len(sp.EDB)
+		if err := sp.Delete("3", []int{6, 20, 6}); err != nil {
+			t.Fatalf("Delete returned an error: %v", err)
+		}
+		if err := sp.Delete("4", []int{9}); err != nil {
+			t.Fatalf("Delete returned an error: %v", err)
+		}
+		PT, _ := sp.TPath("3")
+		PT4, _ := sp.TPath("4")
+		if len(sp.EDB) != before+len(PT)+len(PT4) {
+			t.Errorf("EDB size mismatch after Delete: expected %d, got %d", before+len(PT)+len(PT4), len(sp.EDB))
+		}
+
+		queries := map[[2]string][]int{
+			{"3", "3"}: {7},
+			{"2", "4"}: {2, 4, 5, 7, 8, 10, 11},
+			{"1", "5"}: {1, 2, 3, 4, 5, 7, 8, 10, 11, 12, 13, 14, 15, 16},
+		}
+		check := func(stage string) {
+			for queryRange, expected := range queries {
+				actual := searchIDs(t, sp, queryRange, sortedKeywords)
+				for _, id := range actual {
+					if deleted[id] {
+						t.Errorf("PlaintextAblation=%v %s: deleted ID %d returned for %v", sp.PlaintextAblation, stage, id, queryRange)
+					}
+				}
+				if !reflect.DeepEqual(actual, expected) {
+					t.Errorf("PlaintextAblation=%v %s: search %v mismatch: expected %v, got %v", sp.PlaintextAblation, stage, queryRange, expected, actual)
+				}
+			}
+		}
+		check("after Delete")
+
+		queryRange := [2]string{"1", "5"}
+		K_w_set, ST_set, c_set, err := sp.GenToken(queryRange, sortedKeywords)
+		if err != nil {
+			t.Fatalf("GenToken returned an error: %v", err)
+		}
+		nodes, err := sp.ServerSearchNodes(K_w_set, ST_set, c_set)
+		if err != nil {
+			t.Fatalf("ServerSearchNodes returned an error: %v", err)
+		}
+		msg, err := sp.Consolidate(queryRange, sortedKeywords, K_w_set, c_set, nodes)
+		if err != nil {
+			t.Fatalf("Consolidate returned an error: %v", err)
+		}
+		sp.ApplyConsolidate(msg)
+		check("after Consolidate")
+
+		// 删除后重新添加的文件再次出现在结果中
+		if err := sp.UpdateBigInt("3", new(big.Int).SetBit(big.NewInt(0), 6, 1)); err != nil {
+			t.Fatalf("UpdateBigInt returned an error: %v", err)
+		}
+		expected := []int{6, 7}
+		if actual := searchIDs(t, sp, [2]string{"3", "3"}, sortedKeywords); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("PlaintextAblation=%v: search after re-adding mismatch: expected %v, got %v", sp.PlaintextAblation, expected, actual)
+		}
+
+		if err := sp.Delete("3", []int{64}); err == nil {
+			t.Errorf("Delete of an ID outside the bitmap should fail")
+		}
+		if err := sp.BuildIndex(map[string][]int{"1": {70}}, []string{"1"}); err == nil {
+			t.Errorf("BuildIndex with an ID outside the bitmap should fail")
+		}
+	}
+}
+
+// TestDelete_invalid 删除从未添加或已删除的文件被拒绝，计数器、EDB 与搜索结果保持不变
+func TestDelete_invalid(t *testing.T) {
+	invertedIndex := map[string][]int{
+		"1": {1, 3},
+		"2": {4, 2, 5},
+		"3": {6, 7},
+		"4": {8, 9, 10, 11},
+		"5": {12, 13, 14, 15, 16},
+	}
+	sortedKeywords := sortKeywords(invertedIndex)
+	queryRange := [2]string{"1", "5"}
+	sp := Setup(64)
+	if err := sp.BuildIndex(invertedIndex, sortedKeywords); err != nil {
+		t.Fatalf("BuildIndex returned an error: %v", err)
+	}
+	if err := sp.Delete("4", []int{9}); err != nil {
+		t.Fatalf("Delete returned an error: %v", err)
+	}
+	expected := []int{1, 2, 3, 4, 5, 6, 7, 8, 10, 11, 12, 13, 14, 15, 16}
+
+	invalid := map[string]func() error{
+		"never added":     func() error { return sp.Delete("3", []int{8}) },
+		"already deleted": func() error { return sp.Delete("4", []int{9}) },
+		"partly invalid":  func() error { return sp.Delete("4", []int{10, 9}) },
+		"batch twice": func() error {
+			_, err := sp.BatchUpdate([]Update{
+				{Keyword: "4", DocIDs: []int{8}, Delete: true},
+				{Keyword: "4", DocIDs: []int{8}, Delete: true},
+			})
+			return err
+		},
+	}
+	for name, deleteFiles := range invalid {
+		before := len(sp.EDB)
+		_, _, c_before, _ := sp.GenToken(queryRange, sortedKeywords)
+		if err := deleteFiles(); err == nil {
+			t.Errorf("%s: invalid delete should fail", name)
+		}
+		_, _, c_after, _ := sp.GenToken(queryRange, sortedKeywords)
+		if len(sp.EDB) != before || !reflect.DeepEqual(c_after, c_before) {
+			t.Errorf("%s: index changed by a rejected delete", name)
+		}
+		if actual := searchIDs(t, sp, queryRange, sortedKeywords); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%s: search mismatch: expected %v, got %v", name, expected, actual)
+		}
+	}
+
+	// 同一批次中先添加再删除是有效的，导出后的客户端状态保留文件记录
+	if _, err := sp.BatchUpdate([]Update{
+		{Keyword: "2", DocIDs: []int{30}},
+		{Keyword: "2", DocIDs: []int{30}, Delete: true},
+	}); err != nil {
+		t.Fatalf("BatchUpdate returned an error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := sp.ExportClient(&buf, []byte("password")); err != nil {
+		t.Fatalf("ExportClient returned an error: %v", err)
+	}
+	imported, err := ImportClient(&buf, []byte("password"))
+	if err != nil {
+		t.Fatalf("ImportClient returned an error: %v", err)
+	}
+	if err := imported.Delete("2", []int{30}); err == nil {
+		t.Errorf("Delete of a file removed in a batch should fail after ImportClient")
+	}
+	if err := imported.Delete("2", []int{4}); err != nil {
+		t.Errorf("Delete after ImportClient returned an error: %v", err)
+	}
+}
+
+// TestUpdate_invalid 重复添加关键词中已存在的文件被拒绝（包括同一批次中的重复添加），计数器、EDB 与搜索结果保持不变
+func TestUpdate_invalid(t *testing.T) {
+	invertedIndex := map[string][]int{
+		"1": {1, 3},
+		"2": {4, 2, 5},
+		"3": {6, 7},
+		"4": {8, 9, 10, 11},
+		"5": {12, 13, 14, 15, 16},
+	}
+	sortedKeywords := sortKeywords(invertedIndex)
+	queryRange := [2]string{"3", "3"}
+	sp := Setup(64)
+	if err := sp.BuildIndex(invertedIndex, sortedKeywords); err != nil {
+		t.Fatalf("BuildIndex returned an error: %v", err)
+	}
+	expected := []int{6, 7}
+
+	invalid := map[string]func() error{
+		"UpdateBigInt": func() error { return sp.UpdateBigInt("3", new(big.Int).SetBit(big.NewInt(0), 6, 1)) },
+		"Update":       func() error { return sp.Update("3", 1<<7) },
+		"partly added": func() error {
+			return sp.UpdateBigInt("3", new(big.Int).SetBit(new(big.Int).SetBit(big.NewInt(0), 20, 1), 7, 1))
+		},
+		"batch twice": func() error {
+			_, err := sp.BatchUpdate([]Update{
+				{Keyword: "3", DocIDs: []int{30}},
+				{Keyword: "3", DocIDs: []int{30}},
+			})
+			return err
+		},
+	}
+	for name, addFiles := range invalid {
+		before := len(sp.EDB)
+		_, _, c_before, _ := sp.GenToken(queryRange, sortedKeywords)
+		if err := addFiles(); err == nil {
+			t.Errorf("%s: adding an existing file should fail", name)
+		}
+		_, _, c_after, _ := sp.GenToken(queryRange, sortedKeywords)
+		if len(sp.EDB) != before || !reflect.DeepEqual(c_after, c_before) {
+			t.Errorf("%s: index changed by a rejected update", name)
+		}
+		if actual := searchIDs(t, sp, queryRange, sortedKeywords); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%s: search mismatch: expected %v, got %v", name, expected, actual)
+		}
+	}
+
+	// 其他关键词中的文件、删除后重新添加的文件可以添加
+	if err := sp.Update("3", 1<<8); err != nil {
+		t.Fatalf("Update returned an error: %v", err)
+	}
+	if _, err := sp.BatchUpdate([]Update{
+		{Keyword: "3", DocIDs: []int{6}, Delete: true},
+		{Keyword: "3", DocIDs: []int{6}},
+	}); err != nil {
+		t.Fatalf("BatchUpdate returned an error: %v", err)
+	}
+	if actual, expected := searchIDs(t, sp, queryRange, sortedKeywords), []int{6, 7, 8}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("search after valid updates mismatch: expected %v, got %v", expected, actual)
+	}
+}
+
+// TestBatchUpdate 批量更新为每个受影响的节点只追加一个条目，搜索结果与逐项更新一致；无效的批量更新不改变索引
+func TestBatchUpdate(t *testing.T) {
+	invertedIndex := map[string][]int{
+		"1": {1, 3},
+		"2": {4, 2, 5},
+		"3": {6, 7},
+		"4": {8, 9, 10, 11},
+		"5": {12, 13, 14, 15, 16},
+	}
+	sortedKeywords := sortKeywords(invertedIndex)
+	updates := []Update{
+		{Keyword: "3", DocIDs: []int{20, 21}},
+		{Keyword: "4", DocIDs: []int{22}},
+		{Keyword: "3", DocIDs: []int{6}, Delete: true},
+		{Keyword: "1", DocIDs: []int{23}},
+		{Keyword: "5", DocIDs: []int{12, 16}, Delete: true},
+	}
+	queries := map[[2]string][]int{
+		{"3", "3"}: {7, 20, 21},
+		{"3", "4"}: {7, 8, 9, 10, 11, 20, 21, 22},
+		{"1", "5"}: {1, 2, 3, 4, 5, 7, 8, 9, 10, 11, 13, 14, 15, 20, 21, 22, 23},
+	}
+
+	for _, sp := range []*SystemParameters{Setup(64), SetupPlaintextAblation(64)} {
+		if err := sp.BuildIndex(invertedIndex, sortedKeywords); err != nil {
+			t.Fatalf("BuildIndex returned an error: %v", err)
+		}
+		counters := map[string]int{}
+		for w, info := range sp.CT {
+			counters[w] = info.c
+		}
+		nodes := map[string]bool{}
+		for _, u := range updates {
+			PT, _ := sp.TPath(u.Keyword)
+			for _, w := range PT {
+				nodes[w] = true
+			}
+		}
+
+		before := len(sp.EDB)
+		entries, err := sp.BatchUpdate(updates)
+		if err != nil {
+			t.Fatalf("BatchUpdate returned an error: %v", err)
+		}
+		if len(entries) != len(nodes) || len(sp.EDB) != before+len(nodes) {
+			t.Errorf("BatchUpdate entries mismatch: expected %d, got %d (EDB grew by %d)", len(nodes), len(entries), len(sp.EDB)-before)
+		}
+		for UT, data := range entries {
+			if !reflect.DeepEqual(sp.EDB[UT], data) {
+				t.Errorf("BatchUpdate entry missing from EDB")
+			}
+		}
+		for w := range nodes {
+			if sp.CT[w].c != counters[w]+1 {
+				t.Errorf("counter of node %s mismatch: expected %d, got %d", w, counters[w]+1, sp.CT[w].c)
+			}
+		}
+		for queryRange, expected := range queries {
+			if actual := searchIDs(t, sp, queryRange, sortedKeywords); !reflect.DeepEqual(actual, expected) {
+				t.Errorf("PlaintextAblation=%v: search %v after BatchUpdate mismatch: expected %v, got %v", sp.PlaintextAblation, queryRange, expected, actual)
+			}
+		}
+
+		before = len(sp.EDB)
+		invalid := []Update{{Keyword: "2", DocIDs: []int{30}}, {Keyword: "3", DocIDs: []int{64}}}
+		if _, err := sp.BatchUpdate(invalid); err == nil {
+			t.Errorf("BatchUpdate with an ID outside the bitmap should fail")
+		}
+		if _, err := sp.BatchUpdate([]Update{{Keyword: "2", DocIDs: []int{30}}, {Keyword: "x"}}); err == nil {
+			t.Errorf("BatchUpdate with an invalid keyword should fail")
+		}
+		if len(sp.EDB) != before {
+			t.Errorf("EDB changed by a rejected batch: expected %d entries, got %d", before, len(sp.EDB))
+		}
+		for queryRange, expected := range queries {
+			if actual := searchIDs(t, sp, queryRange, sortedKeywords); !reflect.DeepEqual(actual, expected) {
+				t.Errorf("PlaintextAblation=%v: search %v after rejected batch mismatch: expected %v, got %v", sp.PlaintextAblation, queryRange, expected, actual)
+			}
+		}
+	}
+}
+
+// verifiedSearchIDs 完成一次带证明的搜索并返回文件 ID
+func verifiedSearchIDs(t *testing.T, sp *SystemParameters, queryRange [2]string, sortedKeywords []string) []int {
+	t.Helper()
+	K_w_set, ST_set, c_set, err := sp.GenToken(queryRange, sortedKeywords)
+	if err != nil {
+		t.Fatalf("GenToken returned an error: %v", err)
+	}
+	Sum, proof, err := sp.ServerSearchProof(K_w_set, ST_set, c_set)
+	if err != nil {
+		t.Fatalf("ServerSearchProof returned an error: %v", err)
+	}
+	bs, err := sp.LocalParseVerified(K_w_set, ST_set, c_set, Sum, proof)
+	if err != nil {
+		t.Fatalf("LocalParseVerified returned an error: %v", err)
+	}
+	return bitmapIDs(bs)
+}
+
+// TestVerifiable 可验证模式下诚实服务器的结果通过验证，缺失、替换与重放的条目被拒绝并返回 *VerificationError
+func TestVerifiable(t *testing.T) {
+	invertedIndex := map[string][]int{
+		"1": {1, 3},
+		"2": {4, 2, 5},
+		"3": {6, 7},
+		"4": {8, 9, 10, 11},
+		"5": {12, 13, 14, 15, 16},
+	}
+	sortedKeywords := sortKeywords(invertedIndex)
+	queryRange := [2]string{"2", "4"}
+	sp := Setup(64)
+	sp.Verifiable = true
+	if err := sp.BuildIndex(invertedIndex, sortedKeywords); err != nil {
+		t.Fatalf("BuildIndex returned an error: %v", err)
+	}
+	K_w_set, ST_set, c_set, _ := sp.GenToken(queryRange, sortedKeywords)
+	staleSum, staleProof, _ := sp.ServerSearchProof(K_w_set, ST_set, c_set)
+
+	if err := sp.UpdateBigInt("3", new(big.Int).SetBit(big.NewInt(0), 20, 1)); err != nil {
+		t.Fatalf("UpdateBigInt returned an error: %v", err)
+	}
+	if err := sp.Delete("4", []int{9}); err != nil {
+		t.Fatalf("Delete returned an error: %v", err)
+	}
+	expected := []int{2, 4, 5, 6, 7, 8, 10, 11, 20}
+	if actual := verifiedSearchIDs(t, sp, queryRange, sortedKeywords); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("verified search mismatch: expected %v, got %v", expected, actual)
+	}
+
+	K_w_set, ST_set, c_set, _ = sp.GenToken(queryRange, sortedKeywords)
+	Sum, proof, err := sp.ServerSearchProof(K_w_set, ST_set, c_set)
+	if err != nil {
+		t.Fatalf("ServerSearchProof returned an error: %v", err)
+	}
+	node := -1
+	for i, entries := range proof.Nodes {
+		if len(entries) > 1 {
+			node = i
+		}
+	}
+	if node < 0 {
+		t.Fatalf("expected a node with more than one entry")
+	}
+	tamper := func(modify func(nodes [][]Data) *big.Int) (*big.Int, *SearchProof) {
+		nodes := make([][]Data, len(proof.Nodes))
+		for i, entries := range proof.Nodes {
+			nodes[i] = append([]Data{}, entries...)
+		}
+		return modify(nodes), &SearchProof{Nodes: nodes}
+	}
+	cases := map[string]func() (*big.Int, *SearchProof){
+		"replayed": func() (*big.Int, *SearchProof) { return staleSum, staleProof },
+		"missing":  func() (*big.Int, *SearchProof) { return Sum, nil },
+		"dropped": func() (*big.Int, *SearchProof) {
+			return tamper(func(nodes [][]Data) *big.Int {
+				dropped := nodes[node][len(nodes[node])-1]
+				nodes[node] = nodes[node][:len(nodes[node])-1]
+				return sp.Add(Sum, new(big.Int).Sub(sp.n, dropped.BigIntValue))
+			})
+		},
+		"substituted": func() (*big.Int, *SearchProof) {
+			return tamper(func(nodes [][]Data) *big.Int {
+				nodes[node][0].BigIntValue = sp.Add(nodes[node][0].BigIntValue, big.NewInt(1))
+				return sp.Add(Sum, big.NewInt(1))
+			})
+		},
+		"sum": func() (*big.Int, *SearchProof) {
+			return sp.Add(Sum, big.NewInt(1)), proof
+		},
+	}
+	for name, tampered := range cases {
+		Sum, proof := tampered()
+		_, err := sp.LocalParseVerified(K_w_set, ST_set, c_set, Sum, proof)
+		var verr *VerificationError
+		if !errors.As(err, &verr) {
+			t.Errorf("%s result: expected a *VerificationError, got %v", name, err)
+		}
+	}
+
+	// 合并后的条目同样带有 MAC，MAC 随 EDB 持久化
+	nodes, err := sp.ServerSearchNodes(K_w_set, ST_set, c_set)
+	if err != nil {
+		t.Fatalf("ServerSearchNodes returned an error: %v", err)
+	}
+	msg, err := sp.Consolidate(queryRange, sortedKeywords, K_w_set, c_set, nodes)
+	if err != nil {
+		t.Fatalf("Consolidate returned an error: %v", err)
+	}
+	sp.ApplyConsolidate(msg)
+	var buf bytes.Buffer
+	if err := sp.Save(&buf); err != nil {
+		t.Fatalf("Save returned an error: %v", err)
+	}
+	EDB := sp.EDB
+	sp.EDB = make(map[string]Data)
+	if err := sp.Load(&buf); err != nil {
+		t.Fatalf("Load returned an error: %v", err)
+	}
+	if !reflect.DeepEqual(sp.EDB, EDB) {
+		t.Errorf("EDB mismatch after Load")
+	}
+	if actual := verifiedSearchIDs(t, sp, queryRange, sortedKeywords); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("verified search after Consolidate and Load mismatch: expected %v, got %v", expected, actual)
+	}
+}
+
+// TestVerifiable_builders 各个建索引入口在可验证模式下写入的条目都带有 MAC，验证搜索与普通搜索结果一致
+func TestVerifiable_builders(t *testing.T) {
+	invertedIndex := map[string][]int{
+		"1": {1, 3},
+		"2": {4, 2, 5},
+		"3": {6, 7},
+		"4": {8, 9, 10, 11},
+		"5": {12, 13, 14, 15, 16},
+	}
+	sortedKeywords := sortKeywords(invertedIndex)
+	queryRange := [2]string{"2", "4"}
+	builders := map[string]func(sp *SystemParameters) error{
+		"BuildIndexMock1": func(sp *SystemParameters) error { return sp.BuildIndexMock1(invertedIndex, sortedKeywords) },
+		"BuildIndexMock":  func(sp *SystemParameters) error { return sp.BuildIndexMock(invertedIndex, sortedKeywords) },
+		"BuildIndex_dynamic": func(sp *SystemParameters) error {
+			return sp.BuildIndex_dynamic(invertedIndex, sortedKeywords)
+		},
+	}
+	for name, build := range builders {
+		sp := Setup(64)
+		sp.Verifiable = true
+		if err := build(sp); err != nil {
+			t.Fatalf("%s returned an error: %v", name, err)
+		}
+		expected := searchIDs(t, sp, queryRange, sortedKeywords)
+		if actual := verifiedSearchIDs(t, sp, queryRange, sortedKeywords); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%s: verified search mismatch: expected %v, got %v", name, expected, actual)
+		}
+	}
+}
+
+// TestSaveLoad EDB 写出后重新加载，搜索结果不变；参数不一致时拒绝加载
+func TestSaveLoad(t *testing.T) {
+	invertedIndex := map[string][]int{
+		"1": {1, 3},
+		"2": {4, 2, 5},
+		"3": {6, 7},
+		"4": {8, 9, 10, 11},
+	}
+	sortedKeywords := sortKeywords(invertedIndex)
+	sp := Setup(64)
+	if err := sp.BuildIndex(invertedIndex, sortedKeywords); err != nil {
+		t.Fatalf("BuildIndex returned an error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := sp.Save(&buf); err != nil {
+		t.Fatalf("Save returned an error: %v", err)
+	}
+	saved := buf.Bytes()
+
+	EDB := sp.EDB
+	sp.EDB = make(map[string]Data)
+	if err := sp.Load(bytes.NewReader(saved)); err != nil {
+		t.Fatalf("Load returned an error: %v", err)
+	}
+	if !reflect.DeepEqual(sp.EDB, EDB) {
+		t.Errorf("EDB mismatch after Load")
+	}
+	expected := []int{2, 4, 5, 6, 7}
+	if actual := searchIDs(t, sp, [2]string{"2", "3"}, sortedKeywords); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("search after Load mismatch: expected %v, got %v", expected, actual)
+	}
+
+	if err := Setup(32).Load(bytes.NewReader(saved)); err == nil {
+		t.Errorf("Load with a different BsLength should fail")
+	}
+	if err := sp.Load(bytes.NewReader(saved[:len(saved)-1])); err == nil {
+		t.Errorf("Load of a truncated EDB should fail")
+	}
+}
+
+// TestExportImportClient 导出的客户端状态在新进程中导入后仍能查询原 EDB
+func TestExportImportClient(t *testing.T) {
+	invertedIndex := map[string][]int{
+		"1": {1, 3},
+		"2": {4, 2, 5},
+		"3": {6, 7},
+		"4": {8, 9, 10, 11},
+	}
+	sortedKeywords := sortKeywords(invertedIndex)
+	sp := Setup(64)
+	if err := sp.BuildIndex(invertedIndex, sortedKeywords); err != nil {
+		t.Fatalf("BuildIndex returned an error: %v", err)
+	}
+	if err := sp.UpdateBigInt("3", new(big.Int).SetBit(big.NewInt(0), 20, 1)); err != nil {
+		t.Fatalf("UpdateBigInt returned an error: %v", err)
+	}
+	password := []byte("correct horse battery staple")
+	var state, edb bytes.Buffer
+	if err := sp.ExportClient(&state, password); err != nil {
+		t.Fatalf("ExportClient returned an error: %v", err)
+	}
+	if err := sp.Save(&edb); err != nil {
+		t.Fatalf("Save returned an error: %v", err)
+	}
+
+	imported, err := ImportClient(bytes.NewReader(state.Bytes()), password)
+	if err != nil {
+		t.Fatalf("ImportClient returned an error: %v", err)
+	}
+	if err := imported.Load(&edb); err != nil {
+		t.Fatalf("Load returned an error: %v", err)
+	}
+	expected := []int{2, 4, 5, 6, 7, 20}
+	if actual := searchIDs(t, imported, [2]string{"2", "3"}, sortedKeywords); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("search after ImportClient mismatch: expected %v, got %v", expected, actual)
+	}
+
+	if _, err := ImportClient(bytes.NewReader(state.Bytes()), []byte("wrong password")); err == nil {
+		t.Errorf("ImportClient with a wrong password should fail")
+	}
+}
+
+// TestDomain_signed 负数与稀疏的大数值关键词按值域偏移编码，任意查询范围的结果与明文一致
+func TestDomain_signed(t *testing.T) {
+	keywords := []string{"-122", "-90", "-3", "0", "7", "37", "1000", "335348"}
+	invertedIndex := map[string][]int{}
+	for i, keyword := range keywords {
+		invertedIndex[keyword] = []int{2 * i, 2*i + 1}
+	}
+
+	for _, domain := range []*Domain{nil, {Min: -180, Max: 335348}} {
+		sp := Setup(64)
+		sp.Domain = domain
+		if err := sp.BuildIndex(invertedIndex, keywords); err != nil {
+			t.Fatalf("BuildIndex returned an error: %v", err)
+		}
+		if code := sp.localTreeCode["-122"]; len(code) != sp.TreeHeight+1 || strings.Trim(code, "01") != "" {
+			t.Fatalf("invalid leaf code %q for TreeHeight %d", code, sp.TreeHeight)
+		}
+
+		bounds := []string{"-200", "-122", "-100", "-90", "-1", "0", "7", "8", "500", "1000", "335348", "400000"}
+		for _, left := range bounds {
+			for _, right := range bounds {
+				expected := []int{}
+				l, _ := strconv.Atoi(left)
+				r, _ := strconv.Atoi(right)
+				for i, keyword := range keywords {
+					if v, _ := strconv.Atoi(keyword); v >= l && v <= r {
+						expected = append(expected, 2*i, 2*i+1)
+					}
+				}
+				actual := searchIDs(t, sp, [2]string{left, right}, keywords)
+				if !reflect.DeepEqual(actual, expected) {
+					t.Errorf("domain %v: search [%s, %s] mismatch: expected %v, got %v", domain, left, right, expected, actual)
+				}
+			}
+		}
+	}
+
+	// 显式值域内，建立索引时不存在的关键词也可以更新
+	sp := Setup(64)
+	sp.Domain = &Domain{Min: -180, Max: 180}
+	if err := sp.BuildIndex(map[string][]int{"-10": {1}, "10": {2}}, []string{"-10", "10"}); err != nil {
+		t.Fatalf("BuildIndex returned an error: %v", err)
+	}
+	if err := sp.UpdateBigInt("-179", new(big.Int).SetBit(big.NewInt(0), 3, 1)); err != nil {
+		t.Fatalf("UpdateBigInt returned an error: %v", err)
+	}
+	if actual := searchIDs(t, sp, [2]string{"-180", "0"}, []string{"-179", "-10", "10"}); !reflect.DeepEqual(actual, []int{1, 3}) {
+		t.Errorf("search after Update mismatch: expected %v, got %v", []int{1, 3}, actual)
+	}
+	if err := sp.UpdateBigInt("181", big.NewInt(1)); err == nil {
+		t.Errorf("UpdateBigInt outside the domain should fail")
+	}
+	if err := Setup(64).BuildIndex(map[string][]int{"1.5": {1}}, []string{"1.5"}); err == nil {
+		t.Errorf("BuildIndex with a non-integer keyword should fail")
+	}
+}
+
+// TestRangeCover 直接计算的前缀覆盖与枚举叶子的 preCover 一致
+func TestRangeCover(t *testing.T) {
+	sp := Setup(64)
+	sp.TreeHeight = 4
+	for a := 0; a < 32; a++ {
+		for b := a + 1; b < 32; b++ {
+			if a == 0 && b == 31 {
+				// 全域查询时 preCover 会合并出多一位的根节点编码，rangeCover 使用根的两个子节点
+				if actual := sp.rangeCover(0, 31); !reflect.DeepEqual(actual, []string{"0****", "1****"}) {
+					t.Errorf("full cover mismatch: got %v", actual)
+				}
+				continue
+			}
+			R := make([]int, 0, b-a+1)
+			for v := a; v <= b; v++ {
+				R = append(R, v)
+			}
+			expected, err := sp.preCover(R)
+			if err != nil {
+				t.Fatalf("preCover returned an error: %v", err)
+			}
+			actual := sp.rangeCover(uint64(a), uint64(b))
+			sort.Strings(expected)
+			sort.Strings(actual)
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("cover [%d, %d] mismatch: expected %v, got %v", a, b, expected, actual)
+			}
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package FB_RSSE
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+)
+
+// VerificationError 可验证模式下服务器返回的结果未通过验证：链上条目缺失、MAC 不匹配或密文之和与证明不一致
+type VerificationError struct {
+	Node   int // 未通过验证的 BRC 节点在令牌中的下标，与节点无关时为 -1
+	Reason string
+}
+
+func (e *VerificationError) Error() string {
+	if e.Node < 0 {
+		return fmt.Sprintf("搜索结果验证失败: %s", e.Reason)
+	}
+	return fmt.Sprintf("搜索结果验证失败: 第 %d 个节点: %s", e.Node, e.Reason)
+}
+
+// SearchProof 服务器随搜索结果返回的证明：每个节点的令牌链上从新到旧的全部条目
+type SearchProof struct {
+	Nodes [][]Data
+}
+
+// entryTag 计算节点链上第 c 个条目的 MAC，MAC 密钥由系统密钥派生，服务器无法伪造
+func (sp *SystemParameters) entryTag(UT []byte, c int, e *big.Int, C_ST []byte) []byte {
+	h := hmac.New(sha256.New, sp.PRF([]byte("tag")))
+	h.Write(UT)
+	h.Write(binary.BigEndian.AppendUint64(nil, uint64(c)))
+	eBytes := e.Bytes()
+	h.Write(binary.BigEndian.AppendUint64(nil, uint64(len(eBytes))))
+	h.Write(eBytes)
+	h.Write(C_ST)
+	return h.Sum(nil)
+}
+
+// storeEntry 将节点链上第 c 个条目写入 EDB，可验证模式下同时写入条目的 MAC
+func (sp *SystemParameters) storeEntry(UT []byte, c int, e *big.Int, C_ST []byte) {
+	data := Data{
+		BigIntValue: e,
+		ByteValue:   C_ST,
+	}
+	if sp.Verifiable {
+		data.Tag = sp.entryTag(UT, c, e, C_ST)
+	}
+	sp.EDB[string(UT)] = data
+}
+
+// ServerSearchProof 与 ServerSearch 相同地返回所有节点密文之和，并附带每个节点链上的全部条目作为证明
+func (sp *SystemParameters) ServerSearchProof(K_w_set [][]byte, ST_set [][]byte, c_set []int) (*big.Int, *SearchProof, error) {
+	nodes, err := sp.ServerSearchNodes(K_w_set, ST_set, c_set)
+	if err != nil {
+		return nil, nil, err
+	}
+	Sum := big.NewInt(0)
+	proof := &SearchProof{Nodes: make([][]Data, len(nodes))}
+	for i, node := range nodes {
+		Sum = sp.Add(Sum, node.Sum_e)
+		for _, UT := range node.UTs {
+			proof.Nodes[i] = append(proof.Nodes[i], sp.EDB[string(UT)])
+		}
+	}
+	return Sum, proof, nil
+}
+
+// LocalParseVerified 客户端用自己的 ST 与计数器沿令牌链验证证明：每个节点恰有 c+1 个条目、
+// 每个条目的 MAC 与其地址和序号一致、条目密文之和等于服务器返回的 Sum，全部通过后再解密
+func (sp *SystemParameters) LocalParseVerified(K_w_set [][]byte, ST_set [][]byte, c_set []int, Sum *big.Int, proof *SearchProof) (*big.Int, error) {
+	if proof == nil || len(proof.Nodes) != len(K_w_set) {
+		return nil, &VerificationError{Node: -1, Reason: fmt.Sprintf("证明与 %d 个节点不对应", len(K_w_set))}
+	}
+	total := big.NewInt(0)
+	for i, K_w := range K_w_set {
+		entries := proof.Nodes[i]
+		if len(entries) != c_set[i]+1 {
+			return nil, &VerificationError{Node: i, Reason: fmt.Sprintf("链上有 %d 个条目，期望 %d 个", len(entries), c_set[i]+1)}
+		}
+		ST_j := ST_set[i]
+		for k, data := range entries {
+			if data.BigIntValue == nil {
+				return nil, &VerificationError{Node: i, Reason: "条目为空"}
+			}
+			UT_j := sp.H1(append(K_w, ST_j...))
+			if !hmac.Equal(data.Tag, sp.entryTag(UT_j, c_set[i]-k, data.BigIntValue, data.ByteValue)) {
+				return nil, &VerificationError{Node: i, Reason: fmt.Sprintf("第 %d 个条目的 MAC 不匹配", c_set[i]-k)}
+			}
+			total = sp.Add(total, data.BigIntValue)
+			ST_j, _ = XOR(UT_j, data.ByteValue)
+		}
+	}
+	if Sum == nil || total.Cmp(Sum) != 0 {
+		return nil, &VerificationError{Node: -1, Reason: "密文之和与证明不一致"}
+	}
+	return sp.LocalParse(K_w_set, c_set, Sum)
+}
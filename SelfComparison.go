@@ -20,6 +20,12 @@ func main1() {
 	ranges := []int{6000, 6000 * 2, 6000 * 3, 6000 * 4, 6000 * 5, 6000 * 6, 6000 * 7, 6000 * 8}
 	k := 999999         // 设置最大查询次数
 	resultCounts := 100 // 结果存储的有效查询次数
+	// 结果规模隐藏模式由 config.Padding 选择，默认不填充
+	padding, err := OurScheme.ParsePadding(config.Padding)
+	if err != nil {
+		fmt.Printf("无法解析填充模式: %v\n", err)
+		return
+	}
 
 	// 结果存储目录
 	resultsDir := "results"
@@ -47,6 +53,7 @@ func main1() {
 		for _, L := range LValues {
 			// 初始化 FB_RSSE 和 OurScheme 的对象
 			ours := OurScheme.Setup(L)
+			ours.Padding, ours.BucketSize = padding, config.BucketSize
 			for _, lines := range Lines {
 				sortedKeywords := SortedKeywords[:lines]
 				tempInvertedIndex := make(map[string][]int)
@@ -76,9 +83,10 @@ func main1() {
 
 				writer := csv.NewWriter(resultFile)
 				defer writer.Flush()
-				writer.Write([]string{"Iteration", "Left", "Right", "RangeWidth", "BuildIndex(ns)", "GenToken(ns)", "SearchTokens(ns)", "LocalSearch(ns)", "ClientTimeCost(ns)", "number of tokens"})
+				writer.Write([]string{"Iteration", "Left", "Right", "RangeWidth", "BuildIndex(ns)", "GenToken(ns)", "SearchTokens(ns)", "LocalSearch(ns)", "ClientTimeCost(ns)", "number of tokens", "Results", "PaddedResults", "PaddingOverhead"})
 
 				// 开始测试
+				totalResults, totalPadded := 0, 0 // 所有有效查询的真实结果数与填充后的结果数
 				for _, r := range ranges {
 					// 初始化有效查询计数器
 					validCount := 0
@@ -87,18 +95,18 @@ func main1() {
 
 						// 测量 GenToken 时间（OurScheme）
 						startTime = time.Now()
-						tokensOurs, err := ours.GenToken(queryRange)
+						queryOurs, err := ours.GenToken(queryRange)
 						if err != nil {
 							fmt.Printf("OurScheme GenToken 返回错误: %v", err)
 						}
 						genTokenDurationOurs := time.Since(startTime).Nanoseconds()
 
 						// 如果 tokens 为空，跳过本次循环
-						if len(tokensOurs) == 0 {
+						if len(queryOurs.Tokens) == 0 {
 							searchTokensDuration := 0
 							localSearchDuration := 0
 							clientTimeCost := searchTokensDuration + localSearchDuration
-							writer.Write([]string{fmt.Sprintf("%d", i+1), queryRange[0], queryRange[1], fmt.Sprintf("%d", rangeWidth), fmt.Sprintf("%d", buildIndexDurationOurs), fmt.Sprintf("%d", genTokenDurationOurs), fmt.Sprintf("%d", searchTokensDuration), fmt.Sprintf("%d", localSearchDuration), fmt.Sprintf("%d", clientTimeCost), fmt.Sprintf("%d", len(tokensOurs))})
+							writer.Write([]string{fmt.Sprintf("%d", i+1), queryRange[0], queryRange[1], fmt.Sprintf("%d", rangeWidth), fmt.Sprintf("%d", buildIndexDurationOurs), fmt.Sprintf("%d", genTokenDurationOurs), fmt.Sprintf("%d", searchTokensDuration), fmt.Sprintf("%d", localSearchDuration), fmt.Sprintf("%d", clientTimeCost), fmt.Sprintf("%d", len(queryOurs.Tokens)), "0", "0", "0"})
 							fmt.Printf("Tokens are empty, skipping iteration")
 							continue
 						}
@@ -108,22 +116,28 @@ func main1() {
 
 						// 测量 SearchTokens 时间（OurScheme）
 						startTime = time.Now()
-						searchResultOurs := ours.SearchTokens(tokensOurs)
+						searchResultOurs := ours.SearchTokens(queryOurs)
 						searchTokensDurationOurs := time.Since(startTime).Nanoseconds()
 
-						// 测量 LocalSearch 时间（OurScheme）
+						// 测量 LocalSearch 时间（OurScheme），填充模式下包含填充与去除虚拟文件 ID
 						startTime = time.Now()
-						_, err = ours.LocalSearch(searchResultOurs, tokensOurs)
+						padded, dummies, err := ours.LocalSearchPadded(queryOurs, &OurScheme.SearchResponse{Results: searchResultOurs})
 						if err != nil {
 							fmt.Printf("OurScheme LocalSearch 返回错误: %v", err)
 						}
+						results := padded
+						if ours.Padding != OurScheme.PadNone {
+							results = dummies.Filter(padded)
+						}
 						localSearchDurationOurs := time.Since(startTime).Nanoseconds()
+						totalResults += len(results)
+						totalPadded += len(padded)
 
 						// 计算 ClientTimeCost（OurScheme）
 						clientTimeCostOurs := genTokenDurationOurs + localSearchDurationOurs
 
 						// 写入每次实验的耗时记录（OurScheme）
-						writer.Write([]string{fmt.Sprintf("%d", i+1), queryRange[0], queryRange[1], fmt.Sprintf("%d", rangeWidth), fmt.Sprintf("%d", buildIndexDurationOurs), fmt.Sprintf("%d", genTokenDurationOurs), fmt.Sprintf("%d", searchTokensDurationOurs), fmt.Sprintf("%d", localSearchDurationOurs), fmt.Sprintf("%d", clientTimeCostOurs), fmt.Sprintf("%d", len(tokensOurs))})
+						writer.Write([]string{fmt.Sprintf("%d", i+1), queryRange[0], queryRange[1], fmt.Sprintf("%d", rangeWidth), fmt.Sprintf("%d", buildIndexDurationOurs), fmt.Sprintf("%d", genTokenDurationOurs), fmt.Sprintf("%d", searchTokensDurationOurs), fmt.Sprintf("%d", localSearchDurationOurs), fmt.Sprintf("%d", clientTimeCostOurs), fmt.Sprintf("%d", len(queryOurs.Tokens)), fmt.Sprintf("%d", len(results)), fmt.Sprintf("%d", len(padded)), fmt.Sprintf("%d", len(padded)-len(results))})
 
 						// 如果有效查询次数达到 300 次，停止循环
 						if validCount >= resultCounts {
@@ -136,6 +150,9 @@ func main1() {
 
 				// 打印完成信息
 				fmt.Printf("完成文件: %s, L: %d, 结果存储于: %s\n", file, L, resultFilePathOurs)
+				if totalResults > 0 {
+					fmt.Printf("填充模式: %v, 真实结果: %d, 填充后: %d, 填充开销: %.2f%%\n", padding, totalResults, totalPadded, float64(totalPadded-totalResults)*100/float64(totalResults))
+				}
 			}
 
 		}
@@ -1,14 +1,23 @@
 package OurScheme
 
 import (
+	"EfficientAndLowStroageSSE/codec"
 	"EfficientAndLowStroageSSE/config"
+	"EfficientAndLowStroageSSE/spatial"
 	"EfficientAndLowStroageSSE/tool"
+	"bytes"
+	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math/big"
 	"math/rand"
 	"reflect"
+	"slices"
 	"sort"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 )
@@ -42,8 +51,8 @@ func TestOurScheme_random(t *testing.T) {
 		t.Fatalf("BuildIndex returned an error: %v", err)
 	}
 
-	// 打印 LocalTree 和 ClusterKlist 以供调试
-	t.Logf("LocalTree: %v", sp.LocalTree)
+	// 打印分区边界和 ClusterKlist 以供调试
+	t.Logf("Boundaries: %v", sp.Boundaries())
 	t.Logf("ClusterKlist: %v", sp.ClusterKlist)
 
 	// ------------------------------
@@ -60,18 +69,18 @@ func TestOurScheme_random(t *testing.T) {
 		t.Logf("Query range %d: %v", i+1, queryRange)
 
 		// 第 1 步：生成查询 token
-		tokens, err := sp.GenToken(queryRange)
+		query, err := sp.GenToken(queryRange)
 		if err != nil {
 			t.Fatalf("GenToken returned an error: %v", err)
 		}
-		//t.Logf("Generated tokens for query range %d: %v", i+1, tokens)
+		//t.Logf("Generated tokens for query range %d: %v", i+1, query.Tokens)
 
 		// 第 2 步：从加密数据库中查询加密结果
-		searchResult := sp.SearchTokens(tokens)
+		searchResult := sp.SearchTokens(query)
 		//t.Logf("Search result (encrypted) for query range %d: %v", i+1, searchResult)
 
 		// 第 3 步：执行本地搜索
-		actualSearchResult, err := sp.LocalSearch(searchResult, tokens)
+		actualSearchResult, err := sp.LocalSearch(searchResult, query)
 		if err != nil {
 			t.Fatalf("LocalSearch returned an error: %v", err)
 		}
@@ -111,10 +120,9 @@ func TestOurScheme_fix(t *testing.T) {
 		{"1", "2", "3"},
 		{"4", "50"},
 	}
-	expectedLocalTree := map[string]string{
-		"00": "3",
-		"01": "50",
-		"0":  "3",
+	expectedBoundaries := [][2]int64{
+		{1, 3},
+		{4, 50},
 	}
 
 	// Initialize SystemParameters
@@ -133,7 +141,7 @@ func TestOurScheme_fix(t *testing.T) {
 	// Validate EDB
 	t.Logf("Validating EDB...")
 	for key, expectedValue := range expectedEDB {
-		hashedKey := hex.EncodeToString(sp.H1([]byte(key)))
+		hashedKey := sp.searchToken(key)
 		actualValue, ok := sp.EDB[hashedKey]
 		if !ok {
 			t.Errorf("EDB is missing key: %s", key)
@@ -145,11 +153,11 @@ func TestOurScheme_fix(t *testing.T) {
 		}
 	}
 
-	// Validate LocalTree
-	t.Logf("Validating LocalTree...")
-	t.Logf("Got LocalTree: %v", sp.LocalTree)
-	if !reflect.DeepEqual(sp.LocalTree, expectedLocalTree) {
-		t.Errorf("LocalTree mismatch: expected %v, got %v", expectedLocalTree, sp.LocalTree)
+	// Validate partition boundaries
+	t.Logf("Validating Boundaries...")
+	t.Logf("Got Boundaries: %v", sp.Boundaries())
+	if !reflect.DeepEqual(sp.Boundaries(), expectedBoundaries) {
+		t.Errorf("Boundaries mismatch: expected %v, got %v", expectedBoundaries, sp.Boundaries())
 	}
 
 	// Validate ClusterFlist
@@ -178,24 +186,24 @@ func TestOurScheme_fix(t *testing.T) {
 	expectedSearchResult := []int{1}
 
 	// Step 1: Generate tokens
-	tokens, err := sp.GenToken(queryRange)
+	query, err := sp.GenToken(queryRange)
 	if err != nil {
 		t.Fatalf("GenToken returned an error: %v", err)
 	}
-	t.Logf("Generated tokens: %v", tokens)
+	t.Logf("Generated tokens: %v", query.Tokens)
 
 	// 如果 tokens 为空，直接返回结果为空
-	if len(tokens) == 0 {
+	if len(query.Tokens) == 0 {
 		t.Logf("Tokens are empty, returning empty result")
 		return
 	}
 
 	// Step 2: Query server for search results
-	searchResult := sp.SearchTokens(tokens)
+	searchResult := sp.SearchTokens(query)
 	t.Logf("Search result (encrypted): %v", searchResult)
 
 	// Step 3: Perform local search
-	actualSearchResult, err := sp.LocalSearch(searchResult, tokens)
+	actualSearchResult, err := sp.LocalSearch(searchResult, query)
 	if err != nil {
 		t.Fatalf("LocalSearch returned an error: %v", err)
 	}
@@ -269,11 +277,11 @@ func TestIntegratedScheme(t *testing.T) {
 		t.Errorf("EDB is empty after BuildIndex.")
 	}
 
-	// 验证 LocalTree
-	t.Logf("Validating LocalTree...")
-	t.Logf("Got LocalTree: %v", sp.LocalTree)
-	if len(sp.LocalTree) == 0 {
-		t.Errorf("LocalTree is empty after BuildIndex.")
+	// 验证分区边界
+	t.Logf("Validating Boundaries...")
+	t.Logf("Got Boundaries: %v", sp.Boundaries())
+	if len(sp.Boundaries()) != len(sp.ClusterKlist) {
+		t.Errorf("expected %d partition boundaries after BuildIndex, got %d", len(sp.ClusterKlist), len(sp.Boundaries()))
 	}
 
 	// 验证 ClusterFlist 和 ClusterKlist
@@ -297,18 +305,18 @@ func TestIntegratedScheme(t *testing.T) {
 	expectedSearchResult := simulateSearchResult(queryRange, invertedIndex)
 
 	// Step 1: 生成查询 token
-	tokens, err := sp.GenToken(queryRange)
+	query, err := sp.GenToken(queryRange)
 	if err != nil {
 		t.Fatalf("GenToken returned an error: %v", err)
 	}
-	t.Logf("Generated tokens: %v", tokens)
+	t.Logf("Generated tokens: %v", query.Tokens)
 
 	// Step 2: 从服务器查询加密结果
-	searchResult := sp.SearchTokens(tokens)
+	searchResult := sp.SearchTokens(query)
 	t.Logf("Search result (encrypted): %v", searchResult)
 
 	// Step 3: 在本地解密和处理搜索结果
-	actualSearchResult, err := sp.LocalSearch(searchResult, tokens)
+	actualSearchResult, err := sp.LocalSearch(searchResult, query)
 	if err != nil {
 		t.Fatalf("LocalSearch returned an error: %v", err)
 	}
@@ -373,3 +381,1409 @@ func getRandomKeys(m map[string][]int, count int) []string {
 	}
 	return result
 }
+
+// TestClientServer_split 客户端与服务器分离部署时，经 JSON 序列化的消息往返后结果保持一致
+func TestClientServer_split(t *testing.T) {
+	invertedIndex := map[string][]int{
+		"1": {1, 3},
+		"2": {4, 2, 5},
+		"3": {6, 7},
+		"4": {8, 9, 10, 11},
+		"5": {12, 13, 14, 15, 16},
+	}
+	sortedKeywords := sortKeywords(invertedIndex)
+
+	client := NewClient(10)
+	server := NewServer(10)
+
+	// 客户端构建索引，经序列化后上传到服务器
+	uploadReq, err := client.BuildIndex(invertedIndex, sortedKeywords)
+	if err != nil {
+		t.Fatalf("BuildIndex returned an error: %v", err)
+	}
+	var decodedUpload UpdateRequest
+	roundTripJSON(t, uploadReq, &decodedUpload)
+	if err := server.ApplyUpdate(&decodedUpload); err != nil {
+		t.Fatalf("ApplyUpdate returned an error: %v", err)
+	}
+	if len(server.EDB) != len(invertedIndex) {
+		t.Fatalf("EDB size mismatch: expected %d, got %d", len(invertedIndex), len(server.EDB))
+	}
+
+	queryRange := [2]string{"2", "4"}
+	query, err := client.GenToken(queryRange)
+	if err != nil {
+		t.Fatalf("GenToken returned an error: %v", err)
+	}
+	var decodedReq SearchRequest
+	roundTripJSON(t, query.Request(), &decodedReq)
+
+	resp := server.Search(&decodedReq)
+	var decodedResp SearchResponse
+	roundTripJSON(t, resp, &decodedResp)
+
+	result, err := client.LocalSearch(query, &decodedResp)
+	if err != nil {
+		t.Fatalf("LocalSearch returned an error: %v", err)
+	}
+	sort.Ints(result)
+	expected := []int{2, 4, 5, 6, 7, 8, 9, 10, 11}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Search result mismatch: expected %v, got %v", expected, result)
+	}
+}
+
+// roundTripJSON 将消息编码为 JSON 后再解码，模拟网络传输
+func roundTripJSON(t *testing.T, in interface{}, out interface{}) {
+	t.Helper()
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("json.Marshal returned an error: %v", err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		t.Fatalf("json.Unmarshal returned an error: %v", err)
+	}
+}
+
+// TestQueryContext_concurrent 多个查询同时进行时，各自的查询上下文互不干扰
+func TestQueryContext_concurrent(t *testing.T) {
+	invertedIndex := map[string][]int{
+		"1": {1, 3},
+		"2": {4, 2, 5},
+		"3": {6, 7},
+		"4": {8, 9, 10, 11},
+		"5": {12, 13, 14, 15, 16},
+		"6": {17, 18},
+		"7": {19},
+	}
+	sp := Setup(6)
+	if err := sp.BuildIndex(invertedIndex, sortKeywords(invertedIndex)); err != nil {
+		t.Fatalf("BuildIndex returned an error: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for start := 1; start <= 7; start++ {
+		for end := start; end <= 7; end++ {
+			wg.Add(1)
+			go func(start, end int) {
+				defer wg.Done()
+				queryRange := [2]string{strconv.Itoa(start), strconv.Itoa(end)}
+				query, err := sp.GenToken(queryRange)
+				if err != nil {
+					t.Errorf("GenToken returned an error: %v", err)
+					return
+				}
+				actual, err := sp.LocalSearch(sp.SearchTokens(query), query)
+				if err != nil {
+					t.Errorf("LocalSearch returned an error: %v", err)
+					return
+				}
+				expected := []int{}
+				for k := start; k <= end; k++ {
+					expected = append(expected, invertedIndex[strconv.Itoa(k)]...)
+				}
+				sort.Ints(actual)
+				sort.Ints(expected)
+				if !reflect.DeepEqual(actual, expected) {
+					t.Errorf("Search result mismatch for %v: expected %v, got %v", queryRange, expected, actual)
+				}
+			}(start, end)
+		}
+	}
+	wg.Wait()
+}
+
+// TestKeyedTokens EDB 地址与 OTP 密钥由系统密钥派生，无法仅凭关键词计算
+func TestKeyedTokens(t *testing.T) {
+	invertedIndex := map[string][]int{
+		"1": {1, 3},
+		"2": {4, 2, 5},
+		"3": {6, 7},
+	}
+	sortedKeywords := sortKeywords(invertedIndex)
+
+	sp := Setup(10)
+	if err := sp.BuildIndex(invertedIndex, sortedKeywords); err != nil {
+		t.Fatalf("BuildIndex returned an error: %v", err)
+	}
+	other := NewClient(10)
+
+	for _, keyword := range sortedKeywords {
+		// 未加密钥的哈希不能作为 EDB 地址
+		unkeyed := sha256.Sum256([]byte(keyword))
+		if _, ok := sp.EDB[hex.EncodeToString(unkeyed[:])]; ok {
+			t.Errorf("EDB is addressed by the unkeyed hash of keyword %s", keyword)
+		}
+		token := sp.searchToken(keyword)
+		if _, ok := sp.EDB[token]; !ok {
+			t.Errorf("EDB is missing the keyed token of keyword %s", keyword)
+		}
+		// 不同密钥的客户端得到不同的 token
+		if token == other.searchToken(keyword) {
+			t.Errorf("clients with different keys derived the same token for keyword %s", keyword)
+		}
+		// 搜索 token 与 OTP 密钥相互独立
+		if token == hex.EncodeToString(sp.otpKey(keyword)) {
+			t.Errorf("search token equals the OTP key for keyword %s", keyword)
+		}
+	}
+}
+
+// TestKeystream_fullLength 长度超过 32 字节的位图被完整加密，且每个条目使用独立的 nonce
+func TestKeystream_fullLength(t *testing.T) {
+	L := 6424
+	sp := NewClient(L)
+	group := []int{1, 2, 3, 4, 5}
+	bitmap := sp.generateBitmap(group)
+	key := sp.otpKey("1")
+
+	first, err := encryptBitmap(key, bitmap)
+	if err != nil {
+		t.Fatalf("encryptBitmap returned an error: %v", err)
+	}
+	second, err := encryptBitmap(key, bitmap)
+	if err != nil {
+		t.Fatalf("encryptBitmap returned an error: %v", err)
+	}
+	if len(first) != nonceSize+len(bitmap) {
+		t.Fatalf("ciphertext length mismatch: expected %d, got %d", nonceSize+len(bitmap), len(first))
+	}
+	if reflect.DeepEqual(first, second) {
+		t.Errorf("two encryptions of the same bitmap are identical")
+	}
+
+	// 前 L-32 字节在旧实现中以明文存储，这里逐段检查不再与明文一致
+	ciphertext := first[nonceSize:]
+	for offset := 0; offset+32 <= len(bitmap)-32; offset += 32 {
+		if reflect.DeepEqual(ciphertext[offset:offset+32], bitmap[offset:offset+32]) {
+			t.Errorf("bytes [%d,%d) are stored in the clear", offset, offset+32)
+		}
+	}
+
+	decrypted, err := decryptBitmap(key, first)
+	if err != nil {
+		t.Fatalf("decryptBitmap returned an error: %v", err)
+	}
+	if !reflect.DeepEqual(decrypted, bitmap) {
+		t.Errorf("decrypted bitmap mismatch: expected %v, got %v", bitmap, decrypted)
+	}
+}
+
+// TestBitmap_packed EDB 中的位图按位压缩为 L/8 字节，并能解析回原分区的文件 ID
+func TestBitmap_packed(t *testing.T) {
+	L := 20
+	sp := NewClient(L)
+	dbList := []int{10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}
+
+	bitmap := sp.generateBitmap(dbList[:9])
+	expectedBitmap := []byte{0xff, 0x80, 0x00}
+	if !reflect.DeepEqual(bitmap, expectedBitmap) {
+		t.Fatalf("bitmap mismatch: expected %08b, got %08b", expectedBitmap, bitmap)
+	}
+	if result := parseFileID(bitmap, dbList); !reflect.DeepEqual(result, dbList[:9]) {
+		t.Errorf("parseFileID mismatch: expected %v, got %v", dbList[:9], result)
+	}
+
+	// 两个前缀位图异或得到中间一段
+	middle := xorBytes(sp.generateBitmap(dbList[:3]), sp.generateBitmap(dbList[:7]))
+	if result := parseFileID(middle, dbList); !reflect.DeepEqual(result, dbList[3:7]) {
+		t.Errorf("parseFileID mismatch: expected %v, got %v", dbList[3:7], result)
+	}
+
+	invertedIndex := map[string][]int{"1": {1, 3}, "2": {4, 2, 5}, "3": {6, 7}}
+	server := NewServer(L)
+	req, err := sp.BuildIndex(invertedIndex, sortKeywords(invertedIndex))
+	if err != nil {
+		t.Fatalf("BuildIndex returned an error: %v", err)
+	}
+	if err := server.ApplyUpdate(req); err != nil {
+		t.Fatalf("ApplyUpdate returned an error: %v", err)
+	}
+	for token, entry := range server.EDB {
+		if len(entry) != nonceSize+bitmapSize(L) {
+			t.Errorf("EDB[%s] length mismatch: expected %d, got %d", token, nonceSize+bitmapSize(L), len(entry))
+		}
+	}
+}
+
+// TestServer_saveLoad 服务器重启后从磁盘格式恢复 EDB，客户端无需重新 BuildIndex
+func TestServer_saveLoad(t *testing.T) {
+	invertedIndex := map[string][]int{
+		"1": {1, 3},
+		"2": {4, 2, 5},
+		"3": {6, 7},
+		"4": {8, 9, 10, 11},
+	}
+	sp := Setup(10)
+	if err := sp.BuildIndex(invertedIndex, sortKeywords(invertedIndex)); err != nil {
+		t.Fatalf("BuildIndex returned an error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := sp.Server.Save(&buf); err != nil {
+		t.Fatalf("Save returned an error: %v", err)
+	}
+	saved := buf.Bytes()
+
+	restarted := NewServer(10)
+	if err := restarted.Load(bytes.NewReader(saved)); err != nil {
+		t.Fatalf("Load returned an error: %v", err)
+	}
+	if !reflect.DeepEqual(restarted.EDB, sp.Server.EDB) {
+		t.Fatalf("EDB mismatch after Load")
+	}
+	query, err := sp.GenToken([2]string{"2", "3"})
+	if err != nil {
+		t.Fatalf("GenToken returned an error: %v", err)
+	}
+	result, err := sp.Client.LocalSearch(query, restarted.Search(query.Request()))
+	if err != nil {
+		t.Fatalf("LocalSearch returned an error: %v", err)
+	}
+	sort.Ints(result)
+	if expected := []int{2, 4, 5, 6, 7}; !reflect.DeepEqual(result, expected) {
+		t.Errorf("Search result mismatch: expected %v, got %v", expected, result)
+	}
+
+	if err := NewServer(20).Load(bytes.NewReader(saved)); err == nil {
+		t.Errorf("Load with a different L should fail")
+	}
+}
+
+// TestClient_exportImport 导出的客户端状态导入后可继续查询原服务器
+func TestClient_exportImport(t *testing.T) {
+	invertedIndex := map[string][]int{
+		"1": {1, 3},
+		"2": {4, 2, 5},
+		"3": {6, 7},
+		"4": {8, 9, 10, 11},
+	}
+	sp := Setup(10)
+	if err := sp.BuildIndex(invertedIndex, sortKeywords(invertedIndex)); err != nil {
+		t.Fatalf("BuildIndex returned an error: %v", err)
+	}
+	password := []byte("correct horse battery staple")
+	var buf bytes.Buffer
+	if err := sp.Client.Export(&buf, password); err != nil {
+		t.Fatalf("Export returned an error: %v", err)
+	}
+
+	client, err := ImportClient(bytes.NewReader(buf.Bytes()), password)
+	if err != nil {
+		t.Fatalf("ImportClient returned an error: %v", err)
+	}
+	query, err := client.GenToken([2]string{"2", "4"})
+	if err != nil {
+		t.Fatalf("GenToken returned an error: %v", err)
+	}
+	result, err := client.LocalSearch(query, sp.Server.Search(query.Request()))
+	if err != nil {
+		t.Fatalf("LocalSearch returned an error: %v", err)
+	}
+	sort.Ints(result)
+	if expected := []int{2, 4, 5, 6, 7, 8, 9, 10, 11}; !reflect.DeepEqual(result, expected) {
+		t.Errorf("Search result mismatch: expected %v, got %v", expected, result)
+	}
+
+	if _, err := ImportClient(bytes.NewReader(buf.Bytes()), []byte("wrong password")); err == nil {
+		t.Errorf("ImportClient with a wrong password should fail")
+	}
+}
+
+// TestCodec_latitude 经定点小数编码后，带符号的纬度关键词可直接建立索引并查询
+func TestCodec_latitude(t *testing.T) {
+	invertedIndex := map[string][]int{
+		"-33.8688": {1, 2},
+		"-0.5":     {3},
+		"0":        {4, 5},
+		"37.7749":  {6},
+		"37.775":   {7, 8},
+		"51.5074":  {9},
+	}
+	keywords := make([]string, 0, len(invertedIndex))
+	for keyword := range invertedIndex {
+		keywords = append(keywords, keyword)
+	}
+	latitude := codec.DefaultFixedPoint()
+	if err := latitude.Sort(keywords); err != nil {
+		t.Fatalf("Sort returned an error: %v", err)
+	}
+
+	sp := Setup(4)
+	sp.Codec = latitude
+	if err := sp.BuildIndex(invertedIndex, keywords); err != nil {
+		t.Fatalf("BuildIndex returned an error: %v", err)
+	}
+	queries := map[[2]string][]int{
+		{"-33.8688", "51.5074"}: {1, 2, 3, 4, 5, 6, 7, 8, 9},
+		{"-1", "37.7749"}:       {3, 4, 5, 6},
+		{"0", "37.775"}:         {4, 5, 6, 7, 8},
+	}
+	for queryRange, expected := range queries {
+		query, err := sp.GenToken(queryRange)
+		if err != nil {
+			t.Fatalf("GenToken %v returned an error: %v", queryRange, err)
+		}
+		result, err := sp.LocalSearch(sp.SearchTokens(query), query)
+		if err != nil {
+			t.Fatalf("LocalSearch %v returned an error: %v", queryRange, err)
+		}
+		sort.Ints(result)
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("Search %v mismatch: expected %v, got %v", queryRange, expected, result)
+		}
+	}
+
+	if _, err := sp.GenToken([2]string{"north", "51.5074"}); err == nil {
+		t.Errorf("GenToken with an invalid bound should fail")
+	}
+	if err := Setup(4).BuildIndex(invertedIndex, keywords); err == nil {
+		t.Errorf("BuildIndex of decimal keywords with the integer codec should fail")
+	}
+}
+
+// TestGenToken_gaps 查询边界落在关键词之间的空隙（包括分区内部与分区之间）时结果与明文一致
+func TestGenToken_gaps(t *testing.T) {
+	invertedIndex := map[string][]int{}
+	keywords := []string{}
+	docID := 0
+	for k := 0; k < 40; k++ {
+		keyword := strconv.Itoa(3*k - 30) // 相邻关键词之间留出空隙
+		keywords = append(keywords, keyword)
+		for n := 0; n <= k%3; n++ {
+			invertedIndex[keyword] = append(invertedIndex[keyword], docID)
+			docID++
+		}
+	}
+	sp := Setup(8)
+	if err := sp.BuildIndex(invertedIndex, keywords); err != nil {
+		t.Fatalf("BuildIndex returned an error: %v", err)
+	}
+
+	for left := -30; left <= 87; left++ {
+		for right := left; right <= 87; right++ {
+			queryRange := [2]string{strconv.Itoa(left), strconv.Itoa(right)}
+			query, err := sp.GenToken(queryRange)
+			if err != nil {
+				t.Fatalf("GenToken %v returned an error: %v", queryRange, err)
+			}
+			result, err := sp.LocalSearch(sp.SearchTokens(query), query)
+			if err != nil {
+				t.Fatalf("LocalSearch %v returned an error: %v", queryRange, err)
+			}
+			expected := []int{}
+			for _, keyword := range keywords {
+				if v, _ := strconv.Atoi(keyword); v >= left && v <= right {
+					expected = append(expected, invertedIndex[keyword]...)
+				}
+			}
+			sort.Ints(result)
+			if !reflect.DeepEqual(result, expected) {
+				t.Fatalf("Search %v mismatch: expected %v, got %v", queryRange, expected, result)
+			}
+		}
+	}
+}
+
+// TestSearchBox 随机签到点上的矩形查询与明文按网格单元判断的结果一致，SearchBoxExact 与按坐标判断的结果一致
+func TestSearchBox(t *testing.T) {
+	grid := spatial.Grid{Bits: 6, LatMin: -90, LatMax: 90, LonMin: -180, LonMax: 180}
+	r := rand.New(rand.NewSource(1))
+	invertedIndex := map[string][]int{}
+	cells := map[int][2]uint32{}
+	points := map[int]spatial.Point{}
+	for id := 0; id < 300; id++ {
+		lat := r.Float64()*180 - 90
+		lon := r.Float64()*360 - 180
+		keyword, err := grid.Keyword(lat, lon)
+		if err != nil {
+			t.Fatalf("Keyword returned an error: %v", err)
+		}
+		x, y, _ := grid.Cell(lat, lon)
+		cells[id] = [2]uint32{x, y}
+		points[id] = spatial.Point{Lat: lat, Lon: lon}
+		invertedIndex[keyword] = append(invertedIndex[keyword], id)
+	}
+	sp := Setup(10)
+	if err := sp.BuildIndex(invertedIndex, sortKeywords(invertedIndex)); err != nil {
+		t.Fatalf("BuildIndex returned an error: %v", err)
+	}
+
+	extra := 0 // SearchBox 返回的位于矩形外的文件数
+	for i := 0; i < 50; i++ {
+		lat1, lat2 := r.Float64()*180-90, r.Float64()*180-90
+		lon1, lon2 := r.Float64()*360-180, r.Float64()*360-180
+		box := spatial.Box{
+			LatMin: min(lat1, lat2), LatMax: max(lat1, lat2),
+			LonMin: min(lon1, lon2), LonMax: max(lon1, lon2),
+		}
+		x1, y1, _ := grid.Cell(box.LatMin, box.LonMin)
+		x2, y2, _ := grid.Cell(box.LatMax, box.LonMax)
+		expected := []int{}
+		for id, cell := range cells {
+			if cell[0] >= x1 && cell[0] <= x2 && cell[1] >= y1 && cell[1] <= y2 {
+				expected = append(expected, id)
+			}
+		}
+		sort.Ints(expected)
+
+		result, err := sp.SearchBox(grid, box)
+		if err != nil {
+			t.Fatalf("SearchBox returned an error: %v", err)
+		}
+		sort.Ints(result)
+		if !reflect.DeepEqual(result, expected) {
+			t.Fatalf("SearchBox %+v mismatch: expected %v, got %v", box, expected, result)
+		}
+
+		exact := []int{}
+		for id, p := range points {
+			if box.Contains(p) {
+				exact = append(exact, id)
+			}
+		}
+		sort.Ints(exact)
+		result, err = sp.SearchBoxExact(grid, box, points)
+		if err != nil {
+			t.Fatalf("SearchBoxExact returned an error: %v", err)
+		}
+		sort.Ints(result)
+		if !reflect.DeepEqual(result, exact) {
+			t.Fatalf("SearchBoxExact %+v mismatch: expected %v, got %v", box, exact, result)
+		}
+		extra += len(expected) - len(exact)
+	}
+	if extra == 0 {
+		t.Errorf("expected SearchBox to return files outside the boxes from boundary cells")
+	}
+	if _, err := sp.SearchBoxExact(grid, spatial.Box{LatMin: -90, LatMax: 90, LonMin: -180, LonMax: 180}, map[int]spatial.Point{}); err == nil {
+		t.Errorf("SearchBoxExact without the file coordinates should fail")
+	}
+}
+
+// TestSearchConjunctive 范围与属性的合取查询与明文计算的结果一致，消息经 JSON 往返后仍可解析
+func TestSearchConjunctive(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	invertedIndex := map[string][]int{}
+	attrIndex := map[string][]int{}
+	attrOf := map[int]string{}
+	for id := 0; id < 200; id++ {
+		keyword := strconv.Itoa(r.Intn(40))
+		value := "loc" + strconv.Itoa(r.Intn(6))
+		invertedIndex[keyword] = append(invertedIndex[keyword], id)
+		attrIndex[value] = append(attrIndex[value], id)
+		attrOf[id] = value
+	}
+	sp := Setup(16)
+	if err := sp.BuildIndex(invertedIndex, sortKeywords(invertedIndex)); err != nil {
+		t.Fatalf("BuildIndex returned an error: %v", err)
+	}
+	if err := sp.BuildAttributeIndex(attrIndex); err != nil {
+		t.Fatalf("BuildAttributeIndex returned an error: %v", err)
+	}
+
+	for i := 0; i < 100; i++ {
+		left := r.Intn(40)
+		right := left + r.Intn(40-left)
+		queryRange := [2]string{strconv.Itoa(left), strconv.Itoa(right)}
+		value := "loc" + strconv.Itoa(r.Intn(7)) // loc6 不存在
+		expected := []int{}
+		for keyword, ids := range invertedIndex {
+			k, _ := strconv.Atoi(keyword)
+			for _, id := range ids {
+				if k >= left && k <= right && attrOf[id] == value {
+					expected = append(expected, id)
+				}
+			}
+		}
+		sort.Ints(expected)
+
+		query, err := sp.GenConjunctiveToken(queryRange, value)
+		if err != nil {
+			t.Fatalf("GenConjunctiveToken returned an error: %v", err)
+		}
+		var decodedReq ConjunctiveRequest
+		roundTripJSON(t, query.Request(), &decodedReq)
+		var decodedResp ConjunctiveResponse
+		roundTripJSON(t, sp.Server.SearchConjunctive(&decodedReq), &decodedResp)
+		result, err := sp.LocalSearchConjunctive(query, &decodedResp)
+		if err != nil {
+			t.Fatalf("LocalSearchConjunctive returned an error: %v", err)
+		}
+		sort.Ints(result)
+		if !reflect.DeepEqual(result, expected) {
+			t.Fatalf("SearchConjunctive %v AND %s mismatch: expected %v, got %v", queryRange, value, expected, result)
+		}
+	}
+}
+
+// checkConjunctive 随机检查合取查询的结果与明文一致
+func checkConjunctive(t *testing.T, sp *OurScheme, r *rand.Rand, invertedIndex map[string][]int, attrOf map[int]string, stage string) {
+	t.Helper()
+	for i := 0; i < 30; i++ {
+		left := r.Intn(40)
+		right := left + r.Intn(40-left)
+		queryRange := [2]string{strconv.Itoa(left), strconv.Itoa(right)}
+		value := "loc" + strconv.Itoa(r.Intn(6))
+		expected := []int{}
+		for keyword, ids := range invertedIndex {
+			k, _ := strconv.Atoi(keyword)
+			for _, id := range ids {
+				if k >= left && k <= right && attrOf[id] == value {
+					expected = append(expected, id)
+				}
+			}
+		}
+		sort.Ints(expected)
+		result, err := sp.SearchConjunctive(queryRange, value)
+		if err != nil {
+			t.Fatalf("SearchConjunctive after %s returned an error: %v", stage, err)
+		}
+		sort.Ints(result)
+		if !reflect.DeepEqual(result, expected) {
+			t.Fatalf("SearchConjunctive %v AND %s after %s mismatch: expected %v, got %v", queryRange, value, stage, expected, result)
+		}
+	}
+}
+
+// TestSearchConjunctive_update 添加、删除与批量更新使文件在分区内移动、分区拆分与合并后，合取查询结果保持正确
+func TestSearchConjunctive_update(t *testing.T) {
+	r := rand.New(rand.NewSource(12))
+	invertedIndex := map[string][]int{}
+	attrIndex := map[string][]int{}
+	attrOf := map[int]string{}
+	for id := 0; id < 60; id++ {
+		keyword := strconv.Itoa(r.Intn(40))
+		value := "loc" + strconv.Itoa(r.Intn(6))
+		invertedIndex[keyword] = append(invertedIndex[keyword], id)
+		attrIndex[value] = append(attrIndex[value], id)
+		attrOf[id] = value
+	}
+	sp := Setup(16)
+	sp.MergeBelow = 6
+	if err := sp.BuildIndex(invertedIndex, sortKeywords(invertedIndex)); err != nil {
+		t.Fatalf("BuildIndex returned an error: %v", err)
+	}
+	if err := sp.BuildAttributeIndex(attrIndex); err != nil {
+		t.Fatalf("BuildAttributeIndex returned an error: %v", err)
+	}
+	checkConjunctive(t, sp, r, invertedIndex, attrOf, "BuildAttributeIndex")
+	initial := len(sp.ClusterFlist)
+
+	// 新文件在添加前登记属性值，添加后参与合取查询
+	for id := 60; id < 160; id++ {
+		keyword := strconv.Itoa(r.Intn(40))
+		attrOf[id] = "loc" + strconv.Itoa(r.Intn(6))
+		sp.Attributes[id] = []string{attrOf[id]}
+		if err := sp.Update(keyword, []*big.Int{big.NewInt(int64(id))}); err != nil {
+			t.Fatalf("Update returned an error: %v", err)
+		}
+		invertedIndex[keyword] = append(invertedIndex[keyword], id)
+		checkConjunctive(t, sp, r, invertedIndex, attrOf, fmt.Sprintf("adding %d", id))
+	}
+	grown := len(sp.ClusterFlist)
+	if grown <= initial {
+		t.Fatalf("partitions were not split: %d before, %d after", initial, grown)
+	}
+
+	for _, keyword := range sortKeywords(invertedIndex) {
+		ids := invertedIndex[keyword]
+		if len(ids) < 2 {
+			continue
+		}
+		removed := ids[:len(ids)-1]
+		docIDs := make([]*big.Int, len(removed))
+		for k, id := range removed {
+			docIDs[k] = big.NewInt(int64(id))
+		}
+		if err := sp.Delete(keyword, docIDs); err != nil {
+			t.Fatalf("Delete returned an error: %v", err)
+		}
+		invertedIndex[keyword] = ids[len(ids)-1:]
+		checkConjunctive(t, sp, r, invertedIndex, attrOf, "deleting from "+keyword)
+	}
+	if len(sp.ClusterFlist) >= grown {
+		t.Fatalf("partitions were not merged: %d before, %d after", grown, len(sp.ClusterFlist))
+	}
+
+	updates := []Update{}
+	for id := 160; id < 200; id++ {
+		keyword := strconv.Itoa(r.Intn(40))
+		attrOf[id] = "loc" + strconv.Itoa(r.Intn(6))
+		sp.Attributes[id] = []string{attrOf[id]}
+		updates = append(updates, Update{Op: OpAdd, Keyword: keyword, DocIDs: []*big.Int{big.NewInt(int64(id))}})
+		invertedIndex[keyword] = append(invertedIndex[keyword], id)
+	}
+	if err := sp.BatchUpdate(updates); err != nil {
+		t.Fatalf("BatchUpdate returned an error: %v", err)
+	}
+	checkConjunctive(t, sp, r, invertedIndex, attrOf, "BatchUpdate")
+
+	// 属性索引随客户端状态导出与导入
+	var buf bytes.Buffer
+	if err := sp.Client.Export(&buf, []byte("password")); err != nil {
+		t.Fatalf("Export returned an error: %v", err)
+	}
+	client, err := ImportClient(&buf, []byte("password"))
+	if err != nil {
+		t.Fatalf("ImportClient returned an error: %v", err)
+	}
+	restored := &OurScheme{Client: client, Server: sp.Server}
+	if err := restored.Delete("0", []*big.Int{big.NewInt(int64(invertedIndex["0"][0]))}); err != nil {
+		t.Fatalf("Delete after ImportClient returned an error: %v", err)
+	}
+	invertedIndex["0"] = invertedIndex["0"][1:]
+	checkConjunctive(t, restored, r, invertedIndex, attrOf, "ImportClient")
+}
+
+// TestDelete 随机删除文件后，所有查询范围的结果与明文倒排索引一致，且被删除的文件不再出现
+func TestDelete(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	invertedIndex := map[string][]int{}
+	for id := 0; id < 150; id++ {
+		keyword := strconv.Itoa(r.Intn(30))
+		invertedIndex[keyword] = append(invertedIndex[keyword], id)
+	}
+	sortedKeywords := sortKeywords(invertedIndex)
+	sp := Setup(12)
+	if err := sp.BuildIndex(invertedIndex, sortedKeywords); err != nil {
+		t.Fatalf("BuildIndex returned an error: %v", err)
+	}
+
+	for round := 0; round < 40; round++ {
+		keyword := sortedKeywords[r.Intn(len(sortedKeywords))]
+		ids := invertedIndex[keyword]
+		deleted := []*big.Int{big.NewInt(1000)} // 不属于该关键词的文件被忽略
+		kept := []int{}
+		for _, id := range ids {
+			if r.Intn(2) == 0 {
+				deleted = append(deleted, big.NewInt(int64(id)))
+			} else {
+				kept = append(kept, id)
+			}
+		}
+		if err := sp.UpdateOp(OpDel, keyword, deleted); err != nil {
+			t.Fatalf("UpdateOp returned an error: %v", err)
+		}
+		invertedIndex[keyword] = kept
+
+		checkAllRanges(t, sp, invertedIndex, fmt.Sprintf("%d deletes", round+1))
+	}
+
+	if err := sp.Delete("1000", []*big.Int{big.NewInt(1)}); err == nil {
+		t.Errorf("Delete of an unindexed keyword should fail")
+	}
+}
+
+// TestRBTree 随机插入删除后中序遍历有序，且满足红黑树性质
+func TestRBTree(t *testing.T) {
+	r := rand.New(rand.NewSource(4))
+	tree := NewRBTree()
+	expected := map[[2]int]bool{}
+	for i := 0; i < 2000; i++ {
+		key, value := int64(r.Intn(20)), r.Intn(50)
+		if r.Intn(3) == 0 {
+			if tree.Delete(key, value) != expected[[2]int{int(key), value}] {
+				t.Fatalf("Delete(%d, %d) result mismatch", key, value)
+			}
+			delete(expected, [2]int{int(key), value})
+		} else {
+			if tree.Insert(key, value) == expected[[2]int{int(key), value}] {
+				t.Fatalf("Insert(%d, %d) result mismatch", key, value)
+			}
+			expected[[2]int{int(key), value}] = true
+		}
+		if tree.Size != len(expected) {
+			t.Fatalf("Size mismatch: expected %d, got %d", len(expected), tree.Size)
+		}
+	}
+
+	nodes := [][2]int{}
+	tree.InOrder(func(key int64, value int) { nodes = append(nodes, [2]int{int(key), value}) })
+	if len(nodes) != len(expected) {
+		t.Fatalf("InOrder length mismatch: expected %d, got %d", len(expected), len(nodes))
+	}
+	for i := 1; i < len(nodes); i++ {
+		if !less(int64(nodes[i-1][0]), nodes[i-1][1], int64(nodes[i][0]), nodes[i][1]) {
+			t.Fatalf("InOrder not sorted at %d: %v, %v", i, nodes[i-1], nodes[i])
+		}
+	}
+
+	// 根为黑色，红色节点没有红色孩子，每条路径上黑色节点数相同
+	if tree.Root.Color {
+		t.Fatalf("root should be black")
+	}
+	var blackHeight func(node *RBNode) int
+	blackHeight = func(node *RBNode) int {
+		if node == tree.NIL {
+			return 1
+		}
+		if node.Color && (node.Left.Color || node.Right.Color) {
+			t.Fatalf("red node (%d, %d) has a red child", node.Key, node.Value)
+		}
+		left, right := blackHeight(node.Left), blackHeight(node.Right)
+		if left != right {
+			t.Fatalf("black height mismatch at (%d, %d): %d != %d", node.Key, node.Value, left, right)
+		}
+		if !node.Color {
+			left++
+		}
+		return left
+	}
+	blackHeight(tree.Root)
+}
+
+// TestUpdate 随机添加与删除文件（包括新关键词）后，所有查询范围的结果与明文倒排索引一致
+func TestUpdate(t *testing.T) {
+	r := rand.New(rand.NewSource(5))
+	// 每个关键词 10 个文件，L=29 时每个分区 2 个关键词，留有 8 个文件的空间
+	invertedIndex := map[string][]int{}
+	for id := 0; id < 120; id++ {
+		keyword := strconv.Itoa(10 + 2*(id/10))
+		invertedIndex[keyword] = append(invertedIndex[keyword], id)
+	}
+	sp := Setup(29)
+	if err := sp.BuildIndex(invertedIndex, sortKeywords(invertedIndex)); err != nil {
+		t.Fatalf("BuildIndex returned an error: %v", err)
+	}
+
+	nextID := 120
+	for round := 0; round < 60; round++ {
+		keyword := strconv.Itoa(5 + r.Intn(35)) // 包括关键词之间的空隙与索引范围之外的关键词
+		if r.Intn(3) == 0 {
+			if _, ok := invertedIndex[keyword]; !ok {
+				continue
+			}
+			ids := invertedIndex[keyword]
+			if len(ids) == 0 {
+				continue
+			}
+			k := r.Intn(len(ids))
+			if err := sp.UpdateOp(OpDel, keyword, []*big.Int{big.NewInt(int64(ids[k]))}); err != nil {
+				t.Fatalf("Delete returned an error: %v", err)
+			}
+			invertedIndex[keyword] = append(ids[:k:k], ids[k+1:]...)
+		} else {
+			docID := []*big.Int{big.NewInt(int64(nextID)), big.NewInt(int64(nextID + 1))}
+			if err := sp.UpdateOp(OpAdd, keyword, docID); err != nil {
+				t.Fatalf("Update returned an error: %v", err)
+			}
+			invertedIndex[keyword] = append(invertedIndex[keyword], nextID, nextID+1)
+			nextID += 2
+		}
+
+		checkAllRanges(t, sp, invertedIndex, fmt.Sprintf("round %d", round))
+	}
+}
+
+// checkAllRanges 检查任意两个关键词之间的查询结果与明文倒排索引一致，并检查分区结构的不变量
+func checkAllRanges(t *testing.T, sp *OurScheme, invertedIndex map[string][]int, stage string) {
+	t.Helper()
+	for p, fileList := range sp.ClusterFlist {
+		if len(fileList) >= sp.Client.L && len(sp.ClusterKlist[p]) > 1 {
+			t.Fatalf("partition %d holds %d files with L=%d after %s", p, len(fileList), sp.Client.L, stage)
+		}
+		total := 0
+		for _, volume := range sp.ClusterVlist[p] {
+			total += volume
+		}
+		if total != len(fileList) || len(sp.ClusterVlist[p]) != len(sp.ClusterKlist[p]) {
+			t.Fatalf("partition %d volumes %v do not match its %d files after %s", p, sp.ClusterVlist[p], len(fileList), stage)
+		}
+		bounds, err := sp.partitionBounds(sp.ClusterKlist[p])
+		if err != nil {
+			t.Fatalf("partitionBounds returned an error: %v", err)
+		}
+		if actual := sp.boundaries.Bounds(p); actual != bounds {
+			t.Fatalf("partition %d bounds mismatch after %s: expected %v, got %v", p, stage, bounds, actual)
+		}
+	}
+	if sp.boundaries.Len() != len(sp.ClusterKlist) {
+		t.Fatalf("boundary tree size mismatch after %s: expected %d, got %d", stage, len(sp.ClusterKlist), sp.boundaries.Len())
+	}
+
+	sortedKeywords := sortKeywords(invertedIndex)
+	for i := 0; i < len(sortedKeywords); i++ {
+		for j := i; j < len(sortedKeywords); j++ {
+			queryRange := [2]string{sortedKeywords[i], sortedKeywords[j]}
+			expected := []int{}
+			for _, keyword := range sortedKeywords[i : j+1] {
+				expected = append(expected, invertedIndex[keyword]...)
+			}
+			sort.Ints(expected)
+			query, err := sp.GenToken(queryRange)
+			if err != nil {
+				t.Fatalf("GenToken returned an error: %v", err)
+			}
+			result, err := sp.Client.LocalSearch(query, sp.Server.Search(query.Request()))
+			if err != nil {
+				t.Fatalf("LocalSearch returned an error: %v", err)
+			}
+			sort.Ints(result)
+			if !reflect.DeepEqual(result, expected) {
+				t.Fatalf("Search %v after %s mismatch: expected %v, got %v", queryRange, stage, expected, result)
+			}
+		}
+	}
+}
+
+// TestSplitMerge 持续添加使分区拆分、持续删除使分区合并，过程中查询结果保持正确
+func TestSplitMerge(t *testing.T) {
+	r := rand.New(rand.NewSource(6))
+	invertedIndex := map[string][]int{}
+	for id := 0; id < 40; id++ {
+		keyword := strconv.Itoa(r.Intn(20))
+		invertedIndex[keyword] = append(invertedIndex[keyword], id)
+	}
+	sp := Setup(16)
+	sp.MergeBelow = 6
+	if err := sp.BuildIndex(invertedIndex, sortKeywords(invertedIndex)); err != nil {
+		t.Fatalf("BuildIndex returned an error: %v", err)
+	}
+	initial := len(sp.ClusterFlist)
+
+	for id := 40; id < 200; id++ {
+		keyword := strconv.Itoa(r.Intn(30))
+		if err := sp.Update(keyword, []*big.Int{big.NewInt(int64(id))}); err != nil {
+			t.Fatalf("Update returned an error: %v", err)
+		}
+		invertedIndex[keyword] = append(invertedIndex[keyword], id)
+		checkAllRanges(t, sp, invertedIndex, fmt.Sprintf("adding %d", id))
+	}
+	grown := len(sp.ClusterFlist)
+	if grown <= initial {
+		t.Fatalf("partitions were not split: %d before, %d after", initial, grown)
+	}
+
+	for id := 0; id < 180; id++ {
+		for keyword, ids := range invertedIndex {
+			if k := indexOfInt(ids, id); k >= 0 {
+				if err := sp.Delete(keyword, []*big.Int{big.NewInt(int64(id))}); err != nil {
+					t.Fatalf("Delete returned an error: %v", err)
+				}
+				invertedIndex[keyword] = append(ids[:k:k], ids[k+1:]...)
+				break
+			}
+		}
+		checkAllRanges(t, sp, invertedIndex, fmt.Sprintf("deleting %d", id))
+	}
+	t.Logf("partitions: %d initial, %d after adds, %d after deletes", initial, grown, len(sp.ClusterFlist))
+	if len(sp.ClusterFlist) >= grown {
+		t.Errorf("partitions were not merged: %d before, %d after", grown, len(sp.ClusterFlist))
+	}
+
+	if err := sp.Update("3", []*big.Int{}); err != nil {
+		t.Fatalf("Update returned an error: %v", err)
+	}
+	tooMany := make([]*big.Int, sp.Client.L+1)
+	for i := range tooMany {
+		tooMany[i] = big.NewInt(int64(1000 + i))
+	}
+	if err := sp.Update("3", tooMany); err == nil {
+		t.Errorf("Update beyond L files for one keyword should fail")
+	}
+}
+
+// indexOfInt 返回 value 在 slice 中的下标，不存在时返回 -1
+func indexOfInt(slice []int, value int) int {
+	for i, v := range slice {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
+
+// TestBoundaryTree 随机插入、删除分区并修改边界后，边界树的顺序、点定位与平衡性与切片模拟一致
+func TestBoundaryTree(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	expected := [][2]int64{}
+	for i := 0; i < 10; i++ {
+		expected = append(expected, [2]int64{int64(10 * i), int64(10*i + 5)})
+	}
+	tree := newBoundaryTree(expected)
+
+	for round := 0; round < 500; round++ {
+		if r.Intn(2) == 0 || len(expected) < 2 {
+			p := r.Intn(len(expected) + 1)
+			expected = append(expected[:p], append([][2]int64{{}}, expected[p:]...)...)
+			tree.Insert(p, [2]int64{})
+		} else {
+			p := r.Intn(len(expected))
+			expected = append(expected[:p], expected[p+1:]...)
+			tree.Remove(p)
+		}
+		// 插入或删除后通过 Set 重新赋予有序的边界
+		for p := range expected {
+			expected[p] = [2]int64{int64(10 * p), int64(10*p + 5)}
+			tree.Set(p, expected[p])
+		}
+		if tree.Len() != len(expected) {
+			t.Fatalf("Len mismatch: expected %d, got %d", len(expected), tree.Len())
+		}
+		for p := range expected {
+			if tree.Bounds(p) != expected[p] {
+				t.Fatalf("Bounds(%d) mismatch: expected %v, got %v", p, expected[p], tree.Bounds(p))
+			}
+		}
+		for value := int64(0); value <= int64(10*len(expected)-5); value++ {
+			want := sort.Search(len(expected), func(p int) bool { return expected[p][1] >= value })
+			got, err := tree.Locate(value)
+			if err != nil {
+				t.Fatalf("Locate(%d) returned an error: %v", value, err)
+			}
+			if got != want {
+				t.Fatalf("Locate(%d) mismatch: expected %d, got %d", value, want, got)
+			}
+		}
+		if _, err := tree.Locate(int64(10 * len(expected))); err == nil {
+			t.Fatalf("Locate beyond the last partition should fail")
+		}
+	}
+
+	var check func(n *boundaryNode) int
+	check = func(n *boundaryNode) int {
+		if n == nil {
+			return 0
+		}
+		left, right := check(n.left), check(n.right)
+		if left-right > 1 || right-left > 1 {
+			t.Fatalf("unbalanced node: heights %d and %d", left, right)
+		}
+		return 1 + max(left, right)
+	}
+	check(tree.root)
+}
+
+// TestForwardPrivate 前向安全模式下更新写入的地址与此前发出的任何查询 token 都不相同，查询结果保持正确；
+// 非前向安全模式下同样的更新会写回已被查询过的地址
+func TestForwardPrivate(t *testing.T) {
+	for _, forwardPrivate := range []bool{true, false} {
+		r := rand.New(rand.NewSource(8))
+		invertedIndex := map[string][]int{}
+		for id := 0; id < 80; id++ {
+			keyword := strconv.Itoa(r.Intn(20))
+			invertedIndex[keyword] = append(invertedIndex[keyword], id)
+		}
+		sp := Setup(20)
+		sp.ForwardPrivate = forwardPrivate
+		if err := sp.BuildIndex(invertedIndex, sortKeywords(invertedIndex)); err != nil {
+			t.Fatalf("BuildIndex returned an error: %v", err)
+		}
+
+		issued := map[string]bool{}
+		linked := 0
+		for round := 0; round < 30; round++ {
+			// 查询全部范围，记录服务器见过的 token
+			sortedKeywords := sortKeywords(invertedIndex)
+			for i := range sortedKeywords {
+				for j := i; j < len(sortedKeywords); j++ {
+					query, err := sp.GenToken([2]string{sortedKeywords[i], sortedKeywords[j]})
+					if err != nil {
+						t.Fatalf("GenToken returned an error: %v", err)
+					}
+					for _, token := range query.Tokens {
+						issued[token] = true
+					}
+				}
+			}
+
+			keyword := strconv.Itoa(r.Intn(20))
+			id := 80 + round
+			req, err := sp.Client.Update(keyword, []*big.Int{big.NewInt(int64(id))})
+			if err != nil {
+				t.Fatalf("Update returned an error: %v", err)
+			}
+			for token := range req.Entries {
+				if issued[token] {
+					linked++
+				}
+			}
+			if err := sp.Server.ApplyUpdate(req); err != nil {
+				t.Fatalf("ApplyUpdate returned an error: %v", err)
+			}
+			invertedIndex[keyword] = append(invertedIndex[keyword], id)
+			checkAllRanges(t, sp, invertedIndex, fmt.Sprintf("update %d", round))
+		}
+
+		if forwardPrivate && linked != 0 {
+			t.Errorf("%d updated entries are linkable to issued tokens in forward-private mode", linked)
+		}
+
+		// 导出再导入的客户端保留关键词版本号
+		var buf bytes.Buffer
+		if err := sp.Client.Export(&buf, []byte("password")); err != nil {
+			t.Fatalf("Export returned an error: %v", err)
+		}
+		client, err := ImportClient(&buf, []byte("password"))
+		if err != nil {
+			t.Fatalf("ImportClient returned an error: %v", err)
+		}
+		checkAllRanges(t, &OurScheme{Client: client, Server: sp.Server}, invertedIndex, "import")
+		if !forwardPrivate && linked == 0 {
+			t.Errorf("expected updated entries to reuse issued tokens without forward privacy")
+		}
+	}
+}
+
+// TestBatchUpdate 批量添加、删除后查询结果与依次执行 UpdateOp 一致，条目数不多于逐项更新；无效的批量更新不改变客户端状态
+func TestBatchUpdate(t *testing.T) {
+	for _, forwardPrivate := range []bool{false, true} {
+		r := rand.New(rand.NewSource(9))
+		invertedIndex := map[string][]int{}
+		for id := 0; id < 40; id++ {
+			keyword := strconv.Itoa(r.Intn(20))
+			invertedIndex[keyword] = append(invertedIndex[keyword], id)
+		}
+		batch, sequential := Setup(16), Setup(16)
+		for _, sp := range []*OurScheme{batch, sequential} {
+			sp.MergeBelow = 6
+			sp.ForwardPrivate = forwardPrivate
+			if err := sp.BuildIndex(invertedIndex, sortKeywords(invertedIndex)); err != nil {
+				t.Fatalf("BuildIndex returned an error: %v", err)
+			}
+		}
+
+		nextID := 40
+		batchEntries, sequentialEntries := 0, 0
+		for round := 0; round < 12; round++ {
+			updates := []Update{}
+			for k := 0; k < 15; k++ {
+				keyword := strconv.Itoa(r.Intn(30))
+				// 前半程以添加为主使分区拆分，后半程以删除为主使分区合并
+				if ids := invertedIndex[keyword]; len(ids) > 0 && r.Intn(12) < round {
+					id := ids[r.Intn(len(ids))]
+					updates = append(updates, Update{Op: OpDel, Keyword: keyword, DocIDs: []*big.Int{big.NewInt(int64(id))}})
+					pos := indexOfInt(ids, id)
+					invertedIndex[keyword] = append(ids[:pos:pos], ids[pos+1:]...)
+					continue
+				}
+				updates = append(updates, Update{Op: OpAdd, Keyword: keyword, DocIDs: []*big.Int{big.NewInt(int64(nextID))}})
+				invertedIndex[keyword] = append(invertedIndex[keyword], nextID)
+				nextID++
+			}
+
+			req, err := batch.Client.BatchUpdate(updates)
+			if err != nil {
+				t.Fatalf("BatchUpdate returned an error: %v", err)
+			}
+			if err := batch.Server.ApplyUpdate(req); err != nil {
+				t.Fatalf("ApplyUpdate returned an error: %v", err)
+			}
+			batchEntries += len(req.Entries)
+			for _, u := range updates {
+				req, err := sequential.Client.UpdateOp(u.Op, u.Keyword, u.DocIDs)
+				if err != nil {
+					t.Fatalf("UpdateOp returned an error: %v", err)
+				}
+				if err := sequential.Server.ApplyUpdate(req); err != nil {
+					t.Fatalf("ApplyUpdate returned an error: %v", err)
+				}
+				sequentialEntries += len(req.Entries)
+			}
+			stage := fmt.Sprintf("batch %d (forward private %v)", round, forwardPrivate)
+			checkAllRanges(t, batch, invertedIndex, stage)
+			checkAllRanges(t, sequential, invertedIndex, stage)
+		}
+		t.Logf("forward private %v: %d entries batched, %d entries one by one, %d partitions", forwardPrivate, batchEntries, sequentialEntries, len(batch.ClusterFlist))
+		if batchEntries >= sequentialEntries {
+			t.Errorf("BatchUpdate produced %d entries, expected fewer than %d", batchEntries, sequentialEntries)
+		}
+
+		// 最后一项无效时整个批量更新被拒绝，包括此前对新关键词与分区边界的修改
+		klist := slices.Clone(batch.ClusterKlist[len(batch.ClusterKlist)-1])
+		vlists := fmt.Sprint(batch.ClusterVlist)
+		flists := fmt.Sprint(batch.ClusterFlist)
+		invalid := []Update{
+			{Op: OpAdd, Keyword: "100", DocIDs: []*big.Int{big.NewInt(500)}},
+			{Op: OpDel, Keyword: sortKeywords(invertedIndex)[0], DocIDs: []*big.Int{big.NewInt(int64(invertedIndex[sortKeywords(invertedIndex)[0]][0]))}},
+			{Op: OpDel, Keyword: "999", DocIDs: []*big.Int{big.NewInt(1)}},
+		}
+		if _, err := batch.Client.BatchUpdate(invalid); err == nil {
+			t.Fatalf("BatchUpdate with an unknown keyword should fail")
+		}
+		if actual := batch.ClusterKlist[len(batch.ClusterKlist)-1]; !reflect.DeepEqual(actual, klist) {
+			t.Errorf("keywords changed by a rejected batch: expected %v, got %v", klist, actual)
+		}
+		if fmt.Sprint(batch.ClusterVlist) != vlists || fmt.Sprint(batch.ClusterFlist) != flists {
+			t.Errorf("partitions changed by a rejected batch")
+		}
+		checkAllRanges(t, batch, invertedIndex, "rejected batch")
+		if _, err := batch.Client.BatchUpdate([]Update{{Op: Op(5), Keyword: "3"}}); err == nil {
+			t.Errorf("BatchUpdate with an unknown op should fail")
+		}
+	}
+}
+
+// TestVerifiable 可验证模式下诚实服务器的结果全部通过验证，缺失、替换、交换与重放的结果被拒绝并返回 *VerificationError
+func TestVerifiable(t *testing.T) {
+	invertedIndex := map[string][]int{
+		"1": {1, 3},
+		"2": {4, 2, 5},
+		"3": {6, 7},
+		"4": {8, 9, 10, 11},
+		"5": {12, 13, 14, 15, 16},
+	}
+	sp := Setup(64)
+	sp.Verifiable = true
+	if err := sp.BuildIndex(invertedIndex, sortKeywords(invertedIndex)); err != nil {
+		t.Fatalf("BuildIndex returned an error: %v", err)
+	}
+	checkAllRanges(t, sp, invertedIndex, "BuildIndex")
+
+	query, err := sp.GenToken([2]string{"2", "4"})
+	if err != nil {
+		t.Fatalf("GenToken returned an error: %v", err)
+	}
+	if len(query.Tokens) != 2 {
+		t.Fatalf("expected 2 tokens, got %d", len(query.Tokens))
+	}
+	honest := sp.Server.Search(query.Request())
+	stale := &SearchResponse{Results: slices.Clone(honest.Results), Proofs: slices.Clone(honest.Proofs)}
+
+	// 更新关键词 "1" 后其 token 不变，服务器可能返回旧的条目与 MAC
+	if err := sp.Update("1", []*big.Int{big.NewInt(20)}); err != nil {
+		t.Fatalf("Update returned an error: %v", err)
+	}
+	invertedIndex["1"] = append(invertedIndex["1"], 20)
+	checkAllRanges(t, sp, invertedIndex, "Update")
+	query, err = sp.GenToken([2]string{"2", "4"})
+	if err != nil {
+		t.Fatalf("GenToken returned an error: %v", err)
+	}
+	honest = sp.Server.Search(query.Request())
+
+	tampered := map[string]*SearchResponse{
+		"replayed":  stale,
+		"dropped":   {Results: honest.Results[:1], Proofs: honest.Proofs[:1]},
+		"no proofs": {Results: honest.Results},
+		"swapped": {
+			Results: [][]byte{honest.Results[1], honest.Results[0]},
+			Proofs:  [][]byte{honest.Proofs[1], honest.Proofs[0]},
+		},
+	}
+	substituted := slices.Clone(honest.Results[1])
+	substituted[len(substituted)-1] ^= 1
+	tampered["substituted"] = &SearchResponse{Results: [][]byte{honest.Results[0], substituted}, Proofs: honest.Proofs}
+	for name, resp := range tampered {
+		_, err := sp.Client.LocalSearch(query, resp)
+		var verr *VerificationError
+		if !errors.As(err, &verr) {
+			t.Errorf("%s result: expected a *VerificationError, got %v", name, err)
+		}
+	}
+
+	// MAC 随 EDB 持久化，写入次数随客户端状态导出
+	var buf bytes.Buffer
+	if err := sp.Server.Save(&buf); err != nil {
+		t.Fatalf("Save returned an error: %v", err)
+	}
+	server := NewServer(64)
+	if err := server.Load(&buf); err != nil {
+		t.Fatalf("Load returned an error: %v", err)
+	}
+	if err := sp.Client.Export(&buf, []byte("password")); err != nil {
+		t.Fatalf("Export returned an error: %v", err)
+	}
+	client, err := ImportClient(&buf, []byte("password"))
+	if err != nil {
+		t.Fatalf("ImportClient returned an error: %v", err)
+	}
+	checkAllRanges(t, &OurScheme{Client: client, Server: server}, invertedIndex, "reload")
+}
+
+// TestPadding 填充模式下结果与每个分区的贡献填充到档位，虚拟文件 ID 取自真实文件 ID 的范围，
+// 用客户端的虚拟文件 ID 集合去掉后与明文结果一致
+func TestPadding(t *testing.T) {
+	r := rand.New(rand.NewSource(9))
+	invertedIndex := map[string][]int{}
+	for id := 0; id < 120; id++ {
+		keyword := strconv.Itoa(r.Intn(30))
+		invertedIndex[keyword] = append(invertedIndex[keyword], id)
+	}
+	sortedKeywords := sortKeywords(invertedIndex)
+	sp := Setup(16)
+	if err := sp.BuildIndex(invertedIndex, sortedKeywords); err != nil {
+		t.Fatalf("BuildIndex returned an error: %v", err)
+	}
+	if _, err := ParsePadding("pad"); err == nil {
+		t.Errorf("ParsePadding with an unknown name should fail")
+	}
+
+	for _, c := range []struct {
+		padding    Padding
+		bucketSize int
+		valid      func(n int) bool
+	}{
+		{PadNone, 0, func(n int) bool { return true }},
+		{PadPowerOfTwo, 0, func(n int) bool { return n > 0 && n&(n-1) == 0 }},
+		{PadBucket, 8, func(n int) bool { return n > 0 && n%8 == 0 }},
+	} {
+		if mode, err := ParsePadding(c.padding.String()); err != nil || mode != c.padding {
+			t.Errorf("ParsePadding(%q) = %v, %v, expected %v", c.padding.String(), mode, err, c.padding)
+		}
+		sp.Padding, sp.BucketSize = c.padding, c.bucketSize
+		results, padded := 0, 0
+		for i := 0; i < len(sortedKeywords); i++ {
+			for j := i; j < len(sortedKeywords); j++ {
+				queryRange := [2]string{sortedKeywords[i], sortedKeywords[j]}
+				expected := []int{}
+				for _, keyword := range sortedKeywords[i : j+1] {
+					expected = append(expected, invertedIndex[keyword]...)
+				}
+				sort.Ints(expected)
+				query, err := sp.GenToken(queryRange)
+				if err != nil {
+					t.Fatalf("GenToken returned an error: %v", err)
+				}
+				ids, dummies, err := sp.LocalSearchPadded(query, sp.Server.Search(query.Request()))
+				if err != nil {
+					t.Fatalf("LocalSearchPadded returned an error: %v", err)
+				}
+				if !c.valid(len(ids)) {
+					t.Fatalf("%v: padded size %d of %v is not a valid bucket", c.padding, len(ids), queryRange)
+				}
+				if c.padding == PadNone {
+					plain, err := sp.Client.LocalSearch(query, sp.Server.Search(query.Request()))
+					if err != nil {
+						t.Fatalf("LocalSearch returned an error: %v", err)
+					}
+					if !reflect.DeepEqual(ids, plain) {
+						t.Fatalf("%v: LocalSearchPadded of %v should equal LocalSearch: expected %v, got %v", c.padding, queryRange, plain, ids)
+					}
+				}
+				if len(ids) != len(slices.Compact(slices.Sorted(slices.Values(ids)))) {
+					t.Fatalf("%v: padded result of %v contains duplicate ids", c.padding, queryRange)
+				}
+				if len(dummies) != len(ids)-len(expected) {
+					t.Fatalf("%v: expected %d dummies for %v, got %d", c.padding, len(ids)-len(expected), queryRange, len(dummies))
+				}
+				for id := range dummies {
+					if slices.Contains(expected, id) {
+						t.Fatalf("%v: dummy id %d of %v collides with a real result", c.padding, id, queryRange)
+					}
+					// 真实文件 ID 为 0..119，只有结果几乎占满该范围时才向上扩展
+					if id < 0 || id >= max(120, len(ids)) {
+						t.Fatalf("%v: dummy id %d of %v is outside the real id space", c.padding, id, queryRange)
+					}
+				}
+				result := dummies.Filter(ids)
+				sort.Ints(result)
+				if !reflect.DeepEqual(result, expected) {
+					t.Fatalf("%v: search %v mismatch: expected %v, got %v", c.padding, queryRange, expected, result)
+				}
+				results += len(result)
+				padded += len(ids)
+			}
+		}
+		if c.padding == PadNone && padded != results {
+			t.Errorf("%v: expected no padding, got %d ids for %d results", c.padding, padded, results)
+		}
+		t.Logf("%v: %d results padded to %d", c.padding, results, padded)
+	}
+
+	sp.Padding, sp.BucketSize = PadBucket, 0
+	query, _ := sp.GenToken([2]string{sortedKeywords[0], sortedKeywords[3]})
+	if _, _, err := sp.LocalSearchPadded(query, sp.Server.Search(query.Request())); err == nil {
+		t.Errorf("LocalSearchPadded with BucketSize 0 should fail")
+	}
+
+	// 填充设置随客户端状态导出与导入
+	sp.Padding, sp.BucketSize, sp.IDSpace = PadBucket, 32, 1000
+	var buf bytes.Buffer
+	if err := sp.Client.Export(&buf, []byte("password")); err != nil {
+		t.Fatalf("Export returned an error: %v", err)
+	}
+	client, err := ImportClient(&buf, []byte("password"))
+	if err != nil {
+		t.Fatalf("ImportClient returned an error: %v", err)
+	}
+	if client.Padding != PadBucket || client.BucketSize != 32 || client.IDSpace != 1000 {
+		t.Errorf("padding settings mismatch after ImportClient: got %v %d %d", client.Padding, client.BucketSize, client.IDSpace)
+	}
+}
+
+// TestVerifiable_conjunctive 可验证模式下合取查询的属性结果同样经过验证，隐藏、替换、伪造与重放的属性位图被拒绝
+func TestVerifiable_conjunctive(t *testing.T) {
+	r := rand.New(rand.NewSource(13))
+	invertedIndex := map[string][]int{}
+	attrIndex := map[string][]int{}
+	for id := 0; id < 60; id++ {
+		keyword := strconv.Itoa(r.Intn(20))
+		value := "loc" + strconv.Itoa(r.Intn(3))
+		invertedIndex[keyword] = append(invertedIndex[keyword], id)
+		attrIndex[value] = append(attrIndex[value], id)
+	}
+	sp := Setup(16)
+	sp.Verifiable = true
+	if err := sp.BuildIndex(invertedIndex, sortKeywords(invertedIndex)); err != nil {
+		t.Fatalf("BuildIndex returned an error: %v", err)
+	}
+	if err := sp.BuildAttributeIndex(attrIndex); err != nil {
+		t.Fatalf("BuildAttributeIndex returned an error: %v", err)
+	}
+	queryRange := [2]string{sortKeywords(invertedIndex)[0], sortKeywords(invertedIndex)[len(invertedIndex)-1]}
+	query, err := sp.GenConjunctiveToken(queryRange, "loc0")
+	if err != nil {
+		t.Fatalf("GenConjunctiveToken returned an error: %v", err)
+	}
+	if len(query.AttrTokens) < 2 {
+		t.Fatalf("expected at least 2 attribute tokens, got %d", len(query.AttrTokens))
+	}
+	honest := sp.Server.SearchConjunctive(query.Request())
+	if _, err := sp.LocalSearchConjunctive(query, honest); err != nil {
+		t.Fatalf("LocalSearchConjunctive returned an error: %v", err)
+	}
+	stale := &SearchResponse{Results: slices.Clone(honest.Attr.Results), Proofs: slices.Clone(honest.Attr.Proofs)}
+
+	// 更新使第一个分区的属性位图重新写入，服务器可能返回旧的位图与 MAC
+	p := query.Partitions[0]
+	if err := sp.Update(sp.ClusterKlist[p][0], []*big.Int{big.NewInt(100)}); err != nil {
+		t.Fatalf("Update returned an error: %v", err)
+	}
+	query, err = sp.GenConjunctiveToken(queryRange, "loc0")
+	if err != nil {
+		t.Fatalf("GenConjunctiveToken returned an error: %v", err)
+	}
+	honest = sp.Server.SearchConjunctive(query.Request())
+	if _, err := sp.LocalSearchConjunctive(query, honest); err != nil {
+		t.Fatalf("LocalSearchConjunctive after Update returned an error: %v", err)
+	}
+	tamper := func(modify func(attr *SearchResponse)) *ConjunctiveResponse {
+		attr := &SearchResponse{Results: slices.Clone(honest.Attr.Results), Proofs: slices.Clone(honest.Attr.Proofs)}
+		modify(attr)
+		return &ConjunctiveResponse{Range: honest.Range, Attr: attr}
+	}
+	cases := map[string]*ConjunctiveResponse{
+		"replayed": tamper(func(attr *SearchResponse) {
+			if len(stale.Results) == len(attr.Results) {
+				attr.Results, attr.Proofs = stale.Results, stale.Proofs
+			}
+		}),
+		"hidden": tamper(func(attr *SearchResponse) { attr.Results[0], attr.Proofs[0] = nil, nil }),
+		"swapped": tamper(func(attr *SearchResponse) {
+			attr.Results[0], attr.Results[1] = attr.Results[1], attr.Results[0]
+			attr.Proofs[0], attr.Proofs[1] = attr.Proofs[1], attr.Proofs[0]
+		}),
+		"missing proofs": tamper(func(attr *SearchResponse) { attr.Proofs = nil }),
+	}
+	for name, resp := range cases {
+		_, err := sp.LocalSearchConjunctive(query, resp)
+		var verr *VerificationError
+		if !errors.As(err, &verr) {
+			t.Errorf("%s attribute result: expected a *VerificationError, got %v", name, err)
+		}
+	}
+
+	// 从未写入的属性值不能被服务器伪造出结果
+	query, err = sp.GenConjunctiveToken(queryRange, "loc9")
+	if err != nil {
+		t.Fatalf("GenConjunctiveToken returned an error: %v", err)
+	}
+	forged := sp.Server.SearchConjunctive(query.Request())
+	forged.Attr.Results[0] = honest.Attr.Results[0]
+	forged.Attr.Proofs = [][]byte{honest.Attr.Proofs[0]}
+	var verr *VerificationError
+	if _, err := sp.LocalSearchConjunctive(query, forged); !errors.As(err, &verr) {
+		t.Errorf("forged attribute result: expected a *VerificationError, got %v", err)
+	}
+}
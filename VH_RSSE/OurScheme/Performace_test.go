@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
@@ -101,14 +102,14 @@ func TestPerformanceAdvanced_valid(t *testing.T) {
 
 				// 测量 GenToken 时间
 				startTime = time.Now()
-				tokens, err := sp.GenToken(queryRange)
+				query, err := sp.GenToken(queryRange)
 				if err != nil {
 					t.Fatalf("GenToken 返回错误: %v", err)
 				}
 				genTokenDuration := time.Since(startTime).Nanoseconds()
 
 				// 如果 tokens 为空，设置后续耗时为 0 并跳过
-				if len(tokens) == 0 {
+				if len(query.Tokens) == 0 {
 					searchTokensDuration := 0
 					localSearchDuration := 0
 					clientTimeCost := searchTokensDuration + localSearchDuration
@@ -122,12 +123,12 @@ func TestPerformanceAdvanced_valid(t *testing.T) {
 
 				// 测量 SearchTokens 时间
 				startTime = time.Now()
-				searchResult := sp.SearchTokens(tokens)
+				searchResult := sp.SearchTokens(query)
 				searchTokensDuration := time.Since(startTime).Nanoseconds()
 
 				// 测量 LocalSearch 时间
 				startTime = time.Now()
-				_, err = sp.LocalSearch(searchResult, tokens)
+				_, err = sp.LocalSearch(searchResult, query)
 				if err != nil {
 					t.Fatalf("LocalSearch 返回错误: %v", err)
 				}
@@ -227,14 +228,14 @@ func TestPerformanceAdvanced(t *testing.T) {
 
 				// 测量 GenToken 时间
 				startTime = time.Now()
-				tokens, err := sp.GenToken(queryRange)
+				query, err := sp.GenToken(queryRange)
 				if err != nil {
 					t.Fatalf("GenToken 返回错误: %v", err)
 				}
 				genTokenDuration := time.Since(startTime).Nanoseconds()
 
 				// 如果 tokens 为空，设置后续耗时为 0 并跳过
-				if len(tokens) == 0 {
+				if len(query.Tokens) == 0 {
 					searchTokensDuration := 0
 					localSearchDuration := 0
 					clientTimeCost := searchTokensDuration + localSearchDuration
@@ -245,12 +246,12 @@ func TestPerformanceAdvanced(t *testing.T) {
 
 				// 测量 SearchTokens 时间
 				startTime = time.Now()
-				searchResult := sp.SearchTokens(tokens)
+				searchResult := sp.SearchTokens(query)
 				searchTokensDuration := time.Since(startTime).Nanoseconds()
 
 				// 测量 LocalSearch 时间
 				startTime = time.Now()
-				_, err = sp.LocalSearch(searchResult, tokens)
+				_, err = sp.LocalSearch(searchResult, query)
 				if err != nil {
 					t.Fatalf("LocalSearch 返回错误: %v", err)
 				}
@@ -534,24 +535,18 @@ func loadInvertedIndex_csv(filePath string) (map[string][]int, error) {
 
 // TestCombineCSVFile 合并多个 CSV 文件，生成新的 CSV 文件
 func TestCombineCSVFile(t *testing.T) {
-	// 文件列表
-	files := []string{
-		"C:\\Users\\Admin\\Desktop\\GoPros\\EfficientAndLowStroageSSE\\dataset\\split\\DB_1_d_10.csv",
-		"C:\\Users\\Admin\\Desktop\\GoPros\\EfficientAndLowStroageSSE\\dataset\\split\\DB_2_d_10.csv",
-		"C:\\Users\\Admin\\Desktop\\GoPros\\EfficientAndLowStroageSSE\\dataset\\split\\DB_3_d_10.csv",
-		"C:\\Users\\Admin\\Desktop\\GoPros\\EfficientAndLowStroageSSE\\dataset\\split\\DB_4_d_10.csv",
-		"C:\\Users\\Admin\\Desktop\\GoPros\\EfficientAndLowStroageSSE\\dataset\\split\\DB_5_d_10.csv",
-		"C:\\Users\\Admin\\Desktop\\GoPros\\EfficientAndLowStroageSSE\\dataset\\split\\DB_6_d_10.csv",
-		"C:\\Users\\Admin\\Desktop\\GoPros\\EfficientAndLowStroageSSE\\dataset\\split\\DB_7_d_10.csv",
-		"C:\\Users\\Admin\\Desktop\\GoPros\\EfficientAndLowStroageSSE\\dataset\\split\\DB_8_d_10.csv",
-		"C:\\Users\\Admin\\Desktop\\GoPros\\EfficientAndLowStroageSSE\\dataset\\split\\DB_9_d_10.csv",
-		"C:\\Users\\Admin\\Desktop\\GoPros\\EfficientAndLowStroageSSE\\dataset\\split\\DB_10_d_10.csv",
+	// 文件列表（相对于仓库根目录的数据集）
+	files := []string{}
+	for i := 1; i <= 10; i++ {
+		files = append(files, filepath.Join("..", "..", "dataset", "split", fmt.Sprintf("DB_%d_d_10.csv", i)))
 	}
+	// 合并结果写入临时目录
+	outputDir := t.TempDir()
 
 	// 遍历 1 到 10 个文件进行合并
 	for i := 1; i <= 10; i++ {
 		// 打开输出文件
-		outputFile := fmt.Sprintf("C:\\Users\\Admin\\Desktop\\GoPros\\EfficientAndLowStroageSSE\\dataset\\split\\DB_%d_d_10_combined.csv", i)
+		outputFile := filepath.Join(outputDir, fmt.Sprintf("DB_%d_d_10_combined.csv", i))
 		outFile, err := os.Create(outputFile)
 		if err != nil {
 			fmt.Printf("无法创建文件 %s: %v\n", outputFile, err)
@@ -655,13 +650,13 @@ func TestPerformance(t *testing.T) {
 		buildIndexDuration := time.Since(startTime).Seconds() * 1000 // 毫秒
 		t.Logf("BuildIndex 耗时: %.4f ms", buildIndexDuration)
 		startTime = time.Now()
-		tokens, err := sp.GenToken(queryRange)
+		query, err := sp.GenToken(queryRange)
 		if err != nil {
 			t.Fatalf("GenToken 返回错误: %v", err)
 		}
 
 		// 如果 tokens 为空，直接返回结果为空
-		if len(tokens) == 0 {
+		if len(query.Tokens) == 0 {
 			t.Logf("Tokens are empty, returning empty result")
 			return
 		}
@@ -671,13 +666,13 @@ func TestPerformance(t *testing.T) {
 
 		// 测量 SearchTokens 时间
 		startTime = time.Now()
-		searchResult := sp.SearchTokens(tokens)
+		searchResult := sp.SearchTokens(query)
 		searchTokensDuration := time.Since(startTime).Nanoseconds() // 纳秒
 		t.Logf("SearchTokens 耗时: %d ns", searchTokensDuration)
 
 		// 测量 LocalSearch 时间
 		startTime = time.Now()
-		actualSearchResult, err := sp.LocalSearch(searchResult, tokens)
+		actualSearchResult, err := sp.LocalSearch(searchResult, query)
 		if err != nil {
 			t.Fatalf("LocalSearch 返回错误: %v", err)
 		}
@@ -765,14 +760,14 @@ func TestPerformanceAdvanced_Fix_Width(t *testing.T) {
 
 					// 测量 GenToken 时间
 					startTime = time.Now()
-					tokens, err := sp.GenToken(queryRange)
+					query, err := sp.GenToken(queryRange)
 					if err != nil {
 						t.Fatalf("GenToken 返回错误: %v", err)
 					}
 					genTokenDuration := time.Since(startTime).Nanoseconds()
 
 					// 如果 tokens 为空，设置后续耗时为 0 并跳过
-					if len(tokens) == 0 {
+					if len(query.Tokens) == 0 {
 						searchTokensDuration := 0
 						localSearchDuration := 0
 						writer.WriteString(fmt.Sprintf("%d,%d,%d,%d,%d\n", i+1, buildIndexDuration, genTokenDuration, searchTokensDuration, localSearchDuration))
@@ -782,12 +777,12 @@ func TestPerformanceAdvanced_Fix_Width(t *testing.T) {
 
 					// 测量 SearchTokens 时间
 					startTime = time.Now()
-					searchResult := sp.SearchTokens(tokens)
+					searchResult := sp.SearchTokens(query)
 					searchTokensDuration := time.Since(startTime).Nanoseconds()
 
 					// 测量 LocalSearch 时间
 					startTime = time.Now()
-					_, err = sp.LocalSearch(searchResult, tokens)
+					_, err = sp.LocalSearch(searchResult, query)
 					if err != nil {
 						t.Fatalf("LocalSearch 返回错误: %v", err)
 					}
@@ -988,14 +983,14 @@ func TestPerformanceAdvanced1(t *testing.T) {
 
 				// 测量 GenToken 时间
 				startTime = time.Now()
-				tokens, err := sp.GenToken(queryRange)
+				query, err := sp.GenToken(queryRange)
 				if err != nil {
 					t.Fatalf("GenToken 返回错误: %v", err)
 				}
 				genTokenDuration := time.Since(startTime).Nanoseconds()
 
 				// 如果 tokens 为空，设置后续耗时为 0 并跳过
-				if len(tokens) == 0 {
+				if len(query.Tokens) == 0 {
 					searchTokensDuration := 0
 					localSearchDuration := 0
 					writer.WriteString(fmt.Sprintf("%d,%d,%d,%d,%d,%d\n", i+1, rangeWidth, buildIndexDuration, genTokenDuration, searchTokensDuration, localSearchDuration))
@@ -1005,12 +1000,12 @@ func TestPerformanceAdvanced1(t *testing.T) {
 
 				// 测量 SearchTokens 时间
 				startTime = time.Now()
-				searchResult := sp.SearchTokens(tokens)
+				searchResult := sp.SearchTokens(query)
 				searchTokensDuration := time.Since(startTime).Nanoseconds()
 
 				// 测量 LocalSearch 时间
 				startTime = time.Now()
-				_, err = sp.LocalSearch(searchResult, tokens)
+				_, err = sp.LocalSearch(searchResult, query)
 				if err != nil {
 					t.Fatalf("LocalSearch 返回错误: %v", err)
 				}
@@ -0,0 +1,229 @@
+package OurScheme
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"sort"
+)
+
+// Update 批量更新中的一项：对关键词 Keyword 添加或删除文件 DocIDs
+type Update struct {
+	Op      Op
+	Keyword string
+	DocIDs  []*big.Int
+}
+
+// partitionState 批量更新出错时用于恢复分区的快照
+type partitionState struct {
+	klist  []string
+	vlist  []int
+	bounds [2]int64
+}
+
+// BatchUpdate 批量执行更新：先在各分区的红黑树上完成全部添加与删除，再按分区统一拆分、合并，
+// 每个受影响的分区只重新加密一次（从其中最靠前的变化关键词开始），所有条目合并为一条发往服务器的更新消息。
+// 结果与依次调用 Update/Delete 相同；任一项无效时返回错误，客户端状态保持不变
+func (sp *Client) BatchUpdate(updates []Update) (*UpdateRequest, error) {
+	from := make(map[int]int)     // 分区 -> 最靠前的变化关键词下标
+	deleted := make(map[int]bool) // 发生过删除的分区，之后检查是否需要合并
+	saved := make(map[int]partitionState)
+	touch := func(p int) {
+		if _, ok := saved[p]; !ok {
+			saved[p] = partitionState{
+				klist:  append([]string{}, sp.ClusterKlist[p]...),
+				vlist:  append([]int{}, sp.ClusterVlist[p]...),
+				bounds: sp.boundaries.Bounds(p),
+			}
+		}
+	}
+	rollback := func() {
+		for p, state := range saved {
+			sp.ClusterKlist[p] = state.klist
+			sp.ClusterVlist[p] = state.vlist
+			sp.boundaries.Set(p, state.bounds)
+			delete(sp.clusterTrees, p) // 红黑树由未修改的 ClusterFlist 重建
+		}
+	}
+
+	for _, u := range updates {
+		var p, i int
+		var err error
+		switch u.Op {
+		case OpAdd:
+			p, i, err = sp.batchAdd(u, touch)
+		case OpDel:
+			if p, i, err = sp.batchDelete(u, touch); err == nil && i >= 0 {
+				deleted[p] = true
+			}
+		default:
+			err = fmt.Errorf("未知的更新操作 %v", u.Op)
+		}
+		if err != nil {
+			rollback()
+			return nil, err
+		}
+		if i < 0 { // 没有需要更新的文件
+			continue
+		}
+		if f, ok := from[p]; !ok || i < f {
+			from[p] = i
+		}
+	}
+
+	// 分区结构在此之前保持不变，先按红黑树生成所有受影响分区的文件列表
+	touched := make([]int, 0, len(from))
+	for p := range from {
+		if err := sp.syncPartition(p); err != nil {
+			return nil, err
+		}
+		touched = append(touched, p)
+	}
+	// 从后往前拆分与合并，位置较小的分区不受影响；dirty 随分区的增删同步平移，-1 表示无需重新加密
+	sort.Sort(sort.Reverse(sort.IntSlice(touched)))
+	dirty := make([]int, len(sp.ClusterKlist))
+	for p := range dirty {
+		dirty[p] = -1
+	}
+	for p, f := range from {
+		dirty[p] = f
+	}
+	for _, p := range touched {
+		n, err := sp.splitPartition(p)
+		if err != nil {
+			return nil, err
+		}
+		if n > 1 {
+			// 拆分出的其余分区全部重新加密
+			dirty = slices.Insert(dirty, p+1, make([]int, n-1)...)
+			continue
+		}
+		if !deleted[p] {
+			continue
+		}
+		q, leftLen, merged, err := sp.mergePartition(p)
+		if err != nil {
+			return nil, err
+		}
+		if merged {
+			// 合并后右侧分区的关键词全部重新加密
+			f := leftLen
+			if dirty[q] >= 0 {
+				f = min(f, dirty[q])
+			}
+			dirty = slices.Delete(dirty, q+1, q+2)
+			dirty[q] = f
+		}
+	}
+
+	req := NewUpdateRequest()
+	refreshed := []int{}
+	for p, f := range dirty {
+		if f < 0 {
+			continue
+		}
+		if err := sp.refreshPartition(p, min(f, len(sp.ClusterKlist[p])), req); err != nil {
+			return nil, err
+		}
+		refreshed = append(refreshed, p)
+	}
+	if err := sp.refreshAttributes(refreshed, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// batchAdd 在红黑树中加入一项添加操作，返回分区与变化关键词的下标（没有变化时下标为 -1）
+func (sp *Client) batchAdd(u Update, touch func(int)) (int, int, error) {
+	key, err := sp.Codec.Encode(u.Keyword)
+	if err != nil {
+		return 0, 0, fmt.Errorf("无法将关键词[%s]转换为整数: %v", u.Keyword, err)
+	}
+	p, err := sp.locatePartition(key)
+	if err != nil {
+		return 0, 0, fmt.Errorf("定位关键词[%s]分区失败: %v", u.Keyword, err)
+	}
+	tree, err := sp.partitionTree(p)
+	if err != nil {
+		return 0, 0, err
+	}
+	values, err := sp.Codec.EncodeAll(sp.ClusterKlist[p])
+	if err != nil {
+		return 0, 0, err
+	}
+	i := sort.Search(len(values), func(k int) bool { return values[k] >= key })
+	isNew := i == len(values) || values[i] != key
+
+	added := make(map[int]bool, len(u.DocIDs))
+	for _, id := range u.DocIDs {
+		if idInt := int(id.Int64()); !tree.Contains(key, idInt) {
+			added[idInt] = true
+		}
+	}
+	volume := len(added)
+	if !isNew {
+		volume += sp.ClusterVlist[p][i]
+	}
+	if volume > sp.L {
+		return 0, 0, fmt.Errorf("关键词[%s]的文件数 %d 超出位图长度 L=%d", u.Keyword, volume, sp.L)
+	}
+	if len(added) == 0 && !isNew {
+		return p, -1, nil
+	}
+
+	touch(p)
+	if isNew {
+		sp.ClusterKlist[p] = slices.Insert(sp.ClusterKlist[p], i, u.Keyword)
+		sp.ClusterVlist[p] = slices.Insert(sp.ClusterVlist[p], i, 0)
+		// 新关键词成为分区的第一个或最后一个关键词时，后续项按新的边界定位
+		if i == 0 || i == len(sp.ClusterKlist[p])-1 {
+			bounds, err := sp.partitionBounds(sp.ClusterKlist[p])
+			if err != nil {
+				return 0, 0, err
+			}
+			sp.boundaries.Set(p, bounds)
+		}
+	}
+	for id := range added {
+		tree.Insert(key, id)
+	}
+	sp.ClusterVlist[p][i] += len(added)
+	return p, i, nil
+}
+
+// batchDelete 从红黑树中移除一项删除操作的文件，返回分区与变化关键词的下标（没有变化时下标为 -1）
+func (sp *Client) batchDelete(u Update, touch func(int)) (int, int, error) {
+	p, i, err := sp.locateKeyword(u.Keyword)
+	if err != nil {
+		return 0, 0, err
+	}
+	key, err := sp.Codec.Encode(u.Keyword)
+	if err != nil {
+		return 0, 0, err
+	}
+	tree, err := sp.partitionTree(p)
+	if err != nil {
+		return 0, 0, err
+	}
+	touch(p)
+	removed := 0
+	for _, id := range u.DocIDs {
+		if tree.Delete(key, int(id.Int64())) {
+			removed++
+		}
+	}
+	if removed == 0 {
+		return p, -1, nil
+	}
+	sp.ClusterVlist[p][i] -= removed
+	return p, i, nil
+}
+
+// BatchUpdate 在客户端生成批量更新消息，并交由本地服务器执行
+func (sp *OurScheme) BatchUpdate(updates []Update) error {
+	req, err := sp.Client.BatchUpdate(updates)
+	if err != nil {
+		return err
+	}
+	return sp.Server.ApplyUpdate(req)
+}
@@ -0,0 +1,48 @@
+package OurScheme
+
+import "fmt"
+
+// bitmapSize L 位位图按位压缩后的字节数
+func bitmapSize(L int) int {
+	return (L + 7) / 8
+}
+
+// 辅助函数：生成位图，前 len(group) 位为 1，其余为 0，共 sp.L 位（高位在前）
+func (sp *Client) generateBitmap(group []int) []byte {
+	bitmap := make([]byte, bitmapSize(sp.L))
+	ones := len(group)
+	if ones > sp.L {
+		// 分区大小不应超过 L，超出部分无法在位图中表示
+		ones = sp.L
+	}
+	for i := 0; i < ones; i++ {
+		bitmap[i/8] |= 0x80 >> (i % 8)
+	}
+	return bitmap
+}
+
+// xorBytes 对两个等长的位图按位异或
+func xorBytes(a, b []byte) []byte {
+	if len(a) != len(b) {
+		panic(fmt.Sprintf("位图长度不一致: %d != %d", len(a), len(b)))
+	}
+	result := make([]byte, len(a))
+	for i := range a {
+		result[i] = a[i] ^ b[i]
+	}
+	return result
+}
+
+// parseFileID 根据位图中为 1 的位提取对应位置的文件 ID
+func parseFileID(bitmap []byte, dbList []int) []int {
+	result := []int{}
+	for i, id := range dbList {
+		if i/8 >= len(bitmap) { // 超出位图长度则停止
+			break
+		}
+		if bitmap[i/8]&(0x80>>(i%8)) != 0 {
+			result = append(result, id)
+		}
+	}
+	return result
+}
@@ -0,0 +1,232 @@
+package OurScheme
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// ConjunctiveQuery 合取查询的上下文：范围部分沿用 QueryContext，
+// 属性部分为范围覆盖的每个分区各生成一个 token
+type ConjunctiveQuery struct {
+	Range      *QueryContext
+	Value      string   // 精确匹配的属性值
+	Partitions []int    // 属性 token 对应的分区
+	AttrTokens []string // 发往服务器的属性 token，与 Partitions 一一对应
+	// AttrCounters 可验证模式下属性条目的写入次数，与 AttrTokens 一一对应，0 表示该分区没有写入过此属性值
+	AttrCounters []int
+}
+
+// ConjunctiveRequest 合取查询发往服务器的请求
+type ConjunctiveRequest struct {
+	Range *SearchRequest `json:"range"`
+	Attr  *SearchRequest `json:"attr"`
+}
+
+// ConjunctiveResponse 合取查询的服务器响应，分区内不存在该属性值时对应的结果为 nil
+type ConjunctiveResponse struct {
+	Range *SearchResponse `json:"range"`
+	Attr  *SearchResponse `json:"attr"`
+}
+
+// Request 生成发往服务器的合取查询请求
+func (query *ConjunctiveQuery) Request() *ConjunctiveRequest {
+	return &ConjunctiveRequest{
+		Range: query.Range.Request(),
+		Attr:  &SearchRequest{Tokens: query.AttrTokens},
+	}
+}
+
+// BuildAttributeIndex 为第二个属性（如签到地点）建立精确匹配索引：attrIndex 为属性值 -> 文件 ID，
+// 每个分区内的每个属性值对应一个与范围索引等长的加密位图，位图按 ClusterFlist 中的位置标记文件，
+// 因此可与范围查询的结果在同一分区结构上求交。须在 BuildIndex 之后调用；
+// 之后的 Update/Delete/BatchUpdate 会重新生成受影响分区的属性位图
+func (sp *Client) BuildAttributeIndex(attrIndex map[string][]int) (*UpdateRequest, error) {
+	// 文件 ID -> 属性值，不在范围索引中的文件无法参与合取查询
+	sp.Attributes = make(map[int][]string)
+	for value, ids := range attrIndex {
+		for _, id := range ids {
+			sp.Attributes[id] = append(sp.Attributes[id], value)
+		}
+	}
+	req := NewUpdateRequest()
+	for p := range sp.ClusterFlist {
+		if err := sp.refreshAttributePartition(p, req); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+// refreshAttributes 更新后重新生成分区 partitions 的属性位图；分区个数发生变化时其后分区的位置随之平移，
+// 从其中最靠前的分区到末尾全部重新生成，已不存在的分区位置上的位图被覆盖为空位图。未建立属性索引时不做任何事
+func (sp *Client) refreshAttributes(partitions []int, req *UpdateRequest) error {
+	if sp.Attributes == nil || len(partitions) == 0 {
+		return nil
+	}
+	if len(sp.attrValues) != len(sp.ClusterFlist) {
+		for p := slices.Min(partitions); p < max(len(sp.attrValues), len(sp.ClusterFlist)); p++ {
+			if err := sp.refreshAttributePartition(p, req); err != nil {
+				return err
+			}
+		}
+		sp.attrValues = sp.attrValues[:len(sp.ClusterFlist)]
+		return nil
+	}
+	for _, p := range partitions {
+		if err := sp.refreshAttributePartition(p, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refreshAttributePartition 按分区 p 当前的文件列表加密其中每个属性值的位图，
+// 此前写入过而分区中已不再出现的属性值写入空位图，使服务器上的旧位图失效
+func (sp *Client) refreshAttributePartition(p int, req *UpdateRequest) error {
+	bitmaps := make(map[string][]byte) // 属性值 -> 位图
+	if p < len(sp.ClusterFlist) {
+		for i, id := range sp.ClusterFlist[p] {
+			for _, value := range sp.Attributes[id] {
+				bitmap, ok := bitmaps[value]
+				if !ok {
+					bitmap = make([]byte, bitmapSize(sp.L))
+					bitmaps[value] = bitmap
+				}
+				bitmap[i/8] |= 0x80 >> (i % 8)
+			}
+		}
+	}
+	for len(sp.attrValues) <= p {
+		sp.attrValues = append(sp.attrValues, nil)
+	}
+	for _, value := range sp.attrValues[p] {
+		if _, ok := bitmaps[value]; !ok {
+			bitmaps[value] = make([]byte, bitmapSize(sp.L))
+		}
+	}
+
+	values := make([]string, 0, len(bitmaps))
+	for value, bitmap := range bitmaps {
+		encryptedBitmap, err := encryptBitmap(sp.attrOTPKey(p, value), bitmap)
+		if err != nil {
+			return fmt.Errorf("加密分区 %d 中属性值[%s]的位图失败: %v", p, value, err)
+		}
+		token := sp.attrToken(p, value)
+		req.Entries[token] = encryptedBitmap
+		if sp.Verifiable {
+			key := attrKeyword(p, value)
+			sp.Counters[key]++
+			req.MACs[token] = sp.entryMAC(token, sp.Counters[key], encryptedBitmap)
+		}
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	sp.attrValues[p] = values
+	return nil
+}
+
+// GenConjunctiveToken 生成 "关键词在 queryRange 内 且 属性等于 value" 的查询 token
+func (sp *Client) GenConjunctiveToken(queryRange [2]string, value string) (*ConjunctiveQuery, error) {
+	rangeQuery, err := sp.GenToken(queryRange)
+	if err != nil {
+		return nil, err
+	}
+	query := &ConjunctiveQuery{
+		Range:        rangeQuery,
+		Value:        value,
+		Partitions:   []int{},
+		AttrTokens:   []string{},
+		AttrCounters: []int{},
+	}
+	if rangeQuery.Empty {
+		return query, nil
+	}
+	for p := rangeQuery.Position[0]; p <= rangeQuery.Position[1]; p++ {
+		query.Partitions = append(query.Partitions, p)
+		query.AttrTokens = append(query.AttrTokens, sp.attrToken(p, value))
+		query.AttrCounters = append(query.AttrCounters, sp.Counters[attrKeyword(p, value)])
+	}
+	return query, nil
+}
+
+// LocalSearchConjunctive 解密服务器返回的结果，对范围结果与属性位图按分区求交
+func (sp *Client) LocalSearchConjunctive(query *ConjunctiveQuery, resp *ConjunctiveResponse) ([]int, error) {
+	finalResult := []int{}
+	if query.Range.Empty {
+		return finalResult, nil
+	}
+	if resp.Range == nil || resp.Attr == nil {
+		return nil, fmt.Errorf("合取查询的响应不完整")
+	}
+	if len(resp.Attr.Results) != len(query.AttrTokens) {
+		return nil, fmt.Errorf("服务器返回 %d 个属性结果，与 token 数量 %d 不一致", len(resp.Attr.Results), len(query.AttrTokens))
+	}
+	if sp.Verifiable {
+		if err := sp.verifyAttributes(query, resp.Attr); err != nil {
+			return nil, err
+		}
+	}
+	rangeResult, err := sp.LocalSearch(query.Range, resp.Range)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := make(map[int]bool)
+	for k, entry := range resp.Attr.Results {
+		if entry == nil { // 分区内没有该属性值
+			continue
+		}
+		p := query.Partitions[k]
+		bitmap, err := sp.decryptEntry(sp.attrOTPKey(p, query.Value), entry)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range parseFileID(bitmap, sp.ClusterFlist[p]) {
+			matched[id] = true
+		}
+	}
+	for _, id := range rangeResult {
+		if matched[id] {
+			finalResult = append(finalResult, id)
+		}
+	}
+	return finalResult, nil
+}
+
+// SearchConjunctive 服务器执行合取查询：范围部分与 Search 相同，属性部分缺失的 token 返回 nil 以保持位置对应，
+// 保存有 MAC 时属性结果的 MAC 一并返回
+func (s *Server) SearchConjunctive(req *ConjunctiveRequest) *ConjunctiveResponse {
+	attr := &SearchResponse{Results: make([][]byte, len(req.Attr.Tokens))}
+	if len(s.MACs) > 0 {
+		attr.Proofs = make([][]byte, len(req.Attr.Tokens))
+	}
+	for i, token := range req.Attr.Tokens {
+		attr.Results[i] = s.EDB[token]
+		if attr.Proofs != nil {
+			attr.Proofs[i] = s.MACs[token]
+		}
+	}
+	return &ConjunctiveResponse{
+		Range: s.Search(req.Range),
+		Attr:  attr,
+	}
+}
+
+// BuildAttributeIndex 在客户端构建属性索引，并将加密条目上传到本地服务器
+func (sp *OurScheme) BuildAttributeIndex(attrIndex map[string][]int) error {
+	req, err := sp.Client.BuildAttributeIndex(attrIndex)
+	if err != nil {
+		return err
+	}
+	return sp.Server.ApplyUpdate(req)
+}
+
+// SearchConjunctive 查询关键词在 queryRange 内且属性等于 value 的文件
+func (sp *OurScheme) SearchConjunctive(queryRange [2]string, value string) ([]int, error) {
+	query, err := sp.GenConjunctiveToken(queryRange, value)
+	if err != nil {
+		return nil, err
+	}
+	return sp.LocalSearchConjunctive(query, sp.Server.SearchConjunctive(query.Request()))
+}
@@ -0,0 +1,55 @@
+package OurScheme
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+)
+
+// nonceSize 每个 EDB 条目独立的随机 nonce 长度（AES 分组长度）
+const nonceSize = aes.BlockSize
+
+// xorKeyStream 用 AES-CTR 将 key 和 nonce 扩展为与 data 等长的密钥流并异或
+func xorKeyStream(key, nonce, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("初始化 AES 失败: %v", err)
+	}
+	result := make([]byte, len(data))
+	cipher.NewCTR(block, nonce).XORKeyStream(result, data)
+	return result, nil
+}
+
+// encryptBitmap 加密位图，返回 nonce||密文
+func encryptBitmap(key, bitmap []byte) ([]byte, error) {
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("生成 nonce 失败: %v", err)
+	}
+	ciphertext, err := xorKeyStream(key, nonce, bitmap)
+	if err != nil {
+		return nil, err
+	}
+	return append(nonce, ciphertext...), nil
+}
+
+// decryptBitmap 解密 encryptBitmap 生成的 nonce||密文
+func decryptBitmap(key, entry []byte) ([]byte, error) {
+	if len(entry) < nonceSize {
+		return nil, fmt.Errorf("加密位图长度 %d 小于 nonce 长度 %d", len(entry), nonceSize)
+	}
+	return xorKeyStream(key, entry[:nonceSize], entry[nonceSize:])
+}
+
+// decryptEntry 解密服务器返回的 EDB 条目，并校验位图长度
+func (sp *Client) decryptEntry(key, entry []byte) ([]byte, error) {
+	bitmap, err := decryptBitmap(key, entry)
+	if err != nil {
+		return nil, err
+	}
+	if len(bitmap) != bitmapSize(sp.L) {
+		return nil, fmt.Errorf("位图长度 %d 与期望长度 %d 不一致", len(bitmap), bitmapSize(sp.L))
+	}
+	return bitmap, nil
+}
@@ -0,0 +1,235 @@
+package OurScheme
+
+import "fmt"
+
+// boundaryTree 按分区顺序保存每个分区 [最小关键词, 最大关键词] 编码值的平衡树（AVL，按子树大小隐式索引），
+// 分区的插入、删除与边界修改均为 O(log n)，点定位返回最大关键词不小于查询值的第一个分区
+type boundaryTree struct {
+	root *boundaryNode
+}
+
+// boundaryNode 平衡树节点
+type boundaryNode struct {
+	bounds [2]int64
+	left   *boundaryNode
+	right  *boundaryNode
+	height int
+	size   int
+}
+
+// newBoundaryTree 由按分区顺序排列的边界直接构建平衡树
+func newBoundaryTree(bounds [][2]int64) *boundaryTree {
+	var build func(lo, hi int) *boundaryNode
+	build = func(lo, hi int) *boundaryNode {
+		if lo >= hi {
+			return nil
+		}
+		mid := (lo + hi) / 2
+		node := &boundaryNode{bounds: bounds[mid], left: build(lo, mid), right: build(mid+1, hi)}
+		node.update()
+		return node
+	}
+	return &boundaryTree{root: build(0, len(bounds))}
+}
+
+func nodeHeight(n *boundaryNode) int {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+func nodeSize(n *boundaryNode) int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+// update 根据孩子重新计算高度与子树大小
+func (n *boundaryNode) update() {
+	n.height = 1 + max(nodeHeight(n.left), nodeHeight(n.right))
+	n.size = 1 + nodeSize(n.left) + nodeSize(n.right)
+}
+
+func rotateRight(n *boundaryNode) *boundaryNode {
+	l := n.left
+	n.left = l.right
+	l.right = n
+	n.update()
+	l.update()
+	return l
+}
+
+func rotateLeft(n *boundaryNode) *boundaryNode {
+	r := n.right
+	n.right = r.left
+	r.left = n
+	n.update()
+	r.update()
+	return r
+}
+
+// rebalance 更新节点并在左右子树高度差超过 1 时旋转
+func rebalance(n *boundaryNode) *boundaryNode {
+	n.update()
+	switch diff := nodeHeight(n.left) - nodeHeight(n.right); {
+	case diff > 1:
+		if nodeHeight(n.left.left) < nodeHeight(n.left.right) {
+			n.left = rotateLeft(n.left)
+		}
+		return rotateRight(n)
+	case diff < -1:
+		if nodeHeight(n.right.right) < nodeHeight(n.right.left) {
+			n.right = rotateRight(n.right)
+		}
+		return rotateLeft(n)
+	}
+	return n
+}
+
+// Len 分区个数
+func (t *boundaryTree) Len() int {
+	return nodeSize(t.root)
+}
+
+// node 返回第 p 个分区的节点
+func (t *boundaryTree) node(p int) *boundaryNode {
+	n := t.root
+	for n != nil {
+		ls := nodeSize(n.left)
+		switch {
+		case p < ls:
+			n = n.left
+		case p > ls:
+			p -= ls + 1
+			n = n.right
+		default:
+			return n
+		}
+	}
+	return nil
+}
+
+// Bounds 返回第 p 个分区的边界
+func (t *boundaryTree) Bounds(p int) [2]int64 {
+	return t.node(p).bounds
+}
+
+// Set 修改第 p 个分区的边界
+func (t *boundaryTree) Set(p int, bounds [2]int64) {
+	t.node(p).bounds = bounds
+}
+
+// Insert 在位置 p 插入一个分区，原位置及其后的分区后移
+func (t *boundaryTree) Insert(p int, bounds [2]int64) {
+	var insert func(n *boundaryNode, p int) *boundaryNode
+	insert = func(n *boundaryNode, p int) *boundaryNode {
+		if n == nil {
+			return &boundaryNode{bounds: bounds, height: 1, size: 1}
+		}
+		if ls := nodeSize(n.left); p <= ls {
+			n.left = insert(n.left, p)
+		} else {
+			n.right = insert(n.right, p-ls-1)
+		}
+		return rebalance(n)
+	}
+	t.root = insert(t.root, p)
+}
+
+// Remove 删除第 p 个分区，其后的分区前移
+func (t *boundaryTree) Remove(p int) {
+	var remove func(n *boundaryNode, p int) *boundaryNode
+	remove = func(n *boundaryNode, p int) *boundaryNode {
+		ls := nodeSize(n.left)
+		switch {
+		case p < ls:
+			n.left = remove(n.left, p)
+		case p > ls:
+			n.right = remove(n.right, p-ls-1)
+		default:
+			if n.left == nil {
+				return n.right
+			}
+			if n.right == nil {
+				return n.left
+			}
+			// 用右子树中的第一个分区替换当前节点
+			successor := n.right
+			for successor.left != nil {
+				successor = successor.left
+			}
+			n.bounds = successor.bounds
+			n.right = remove(n.right, 0)
+		}
+		return rebalance(n)
+	}
+	t.root = remove(t.root, p)
+}
+
+// Locate 返回最大关键词不小于 value 的第一个分区，value 超出全部分区的范围时返回错误
+func (t *boundaryTree) Locate(value int64) (int, error) {
+	if t.Len() == 0 {
+		return 0, fmt.Errorf("索引为空")
+	}
+	first, last := t.Bounds(0), t.Bounds(t.Len()-1)
+	if value < first[0] || value > last[1] {
+		return 0, fmt.Errorf("queryValueInt %d 在范围[%d,%d]之外，无法找到节点", value, first[0], last[1])
+	}
+	position, offset := -1, 0
+	n := t.root
+	for n != nil {
+		ls := nodeSize(n.left)
+		if n.bounds[1] >= value {
+			position = offset + ls
+			n = n.left
+		} else {
+			offset += ls + 1
+			n = n.right
+		}
+	}
+	return position, nil
+}
+
+// partitionBounds 分区第一个和最后一个关键词的编码值
+func (sp *Client) partitionBounds(klist []string) ([2]int64, error) {
+	if len(klist) == 0 {
+		return [2]int64{}, fmt.Errorf("分区没有关键词")
+	}
+	left, err := sp.Codec.Encode(klist[0])
+	if err != nil {
+		return [2]int64{}, err
+	}
+	right, err := sp.Codec.Encode(klist[len(klist)-1])
+	if err != nil {
+		return [2]int64{}, err
+	}
+	return [2]int64{left, right}, nil
+}
+
+// buildBoundaryTree 由分区关键词列表构建分区边界树
+func (sp *Client) buildBoundaryTree(clusterKlist [][]string) error {
+	bounds := make([][2]int64, 0, len(clusterKlist))
+	for _, klist := range clusterKlist {
+		b, err := sp.partitionBounds(klist)
+		if err != nil {
+			return err
+		}
+		bounds = append(bounds, b)
+	}
+	sp.boundaries = newBoundaryTree(bounds)
+	return nil
+}
+
+// Boundaries 按分区顺序返回每个分区 [最小关键词, 最大关键词] 的编码值
+func (sp *Client) Boundaries() [][2]int64 {
+	if sp.boundaries == nil {
+		return nil
+	}
+	bounds := make([][2]int64, sp.boundaries.Len())
+	for p := range bounds {
+		bounds[p] = sp.boundaries.Bounds(p)
+	}
+	return bounds
+}
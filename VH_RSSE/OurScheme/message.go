@@ -0,0 +1,23 @@
+package OurScheme
+
+// SearchRequest 客户端发往服务器的查询请求
+type SearchRequest struct {
+	Tokens []string `json:"tokens"` // 需要服务器查询的边界 token
+}
+
+// SearchResponse 服务器返回给客户端的查询结果
+type SearchResponse struct {
+	Results [][]byte `json:"results"`          // 与 token 顺序一致的加密位图
+	Proofs  [][]byte `json:"proofs,omitempty"` // 可验证模式下与 Results 一一对应的条目 MAC
+}
+
+// UpdateRequest 客户端发往服务器的更新消息（BuildIndex 上传与 Update 共用）
+type UpdateRequest struct {
+	Entries map[string][]byte `json:"entries"`        // 需要写入 EDB 的 token -> 加密位图
+	MACs    map[string][]byte `json:"macs,omitempty"` // 可验证模式下 token -> 条目 MAC，搜索时随结果返回
+}
+
+// NewUpdateRequest 创建空的更新消息
+func NewUpdateRequest() *UpdateRequest {
+	return &UpdateRequest{Entries: make(map[string][]byte), MACs: make(map[string][]byte)}
+}
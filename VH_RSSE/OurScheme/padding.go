@@ -0,0 +1,147 @@
+package OurScheme
+
+import (
+	"fmt"
+	"math/bits"
+	"math/rand/v2"
+)
+
+// Padding 结果规模隐藏模式：客户端按结果取回文件时，每个分区贡献的文件 ID 与整个结果集
+// 都用虚拟文件 ID 填充到固定的档位，服务器只能看到档位而不是真实的结果数量
+type Padding int
+
+const (
+	PadNone       Padding = iota // 不填充
+	PadPowerOfTwo                // 填充到不小于实际数量的 2 的幂
+	PadBucket                    // 填充到 BucketSize 的整数倍
+)
+
+// String 返回填充模式名称
+func (mode Padding) String() string {
+	switch mode {
+	case PadNone:
+		return "none"
+	case PadPowerOfTwo:
+		return "pow2"
+	case PadBucket:
+		return "bucket"
+	default:
+		return fmt.Sprintf("Padding(%d)", int(mode))
+	}
+}
+
+// ParsePadding 按名称解析填充模式，名称与 String 的返回值一致
+func ParsePadding(name string) (Padding, error) {
+	for _, mode := range []Padding{PadNone, PadPowerOfTwo, PadBucket} {
+		if mode.String() == name {
+			return mode, nil
+		}
+	}
+	return PadNone, fmt.Errorf("未知的填充模式: %q", name)
+}
+
+// paddedSize 按填充模式返回 n 个文件 ID 填充后的数量，空结果同样填充到最小档位
+func (sp *Client) paddedSize(n int) (int, error) {
+	switch sp.Padding {
+	case PadNone:
+		return n, nil
+	case PadPowerOfTwo:
+		if n <= 1 {
+			return 1, nil
+		}
+		return 1 << bits.Len(uint(n-1)), nil
+	case PadBucket:
+		if sp.BucketSize <= 0 {
+			return 0, fmt.Errorf("填充档位 BucketSize=%d 无效", sp.BucketSize)
+		}
+		return max(1, (n+sp.BucketSize-1)/sp.BucketSize) * sp.BucketSize, nil
+	default:
+		return 0, fmt.Errorf("未知的填充模式 %v", sp.Padding)
+	}
+}
+
+// DummySet LocalSearchPadded 填充的虚拟文件 ID 集合，只保存在客户端，用于从取回的文件中去掉虚拟文件
+type DummySet map[int]struct{}
+
+// Contains 判断文件 ID 是否为填充用的虚拟文件
+func (dummies DummySet) Contains(id int) bool {
+	_, ok := dummies[id]
+	return ok
+}
+
+// Filter 去掉填充的虚拟文件 ID，得到真实的查询结果
+func (dummies DummySet) Filter(ids []int) []int {
+	result := []int{}
+	for _, id := range ids {
+		if !dummies.Contains(id) {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// dummySpace 返回虚拟文件 ID 的取值范围 [0, space)：默认为 [0, 最大真实文件 ID]，
+// 范围内去掉已用的 ID 后不足 need 个时向上扩展
+func (sp *Client) dummySpace(used, need int) int {
+	space := sp.IDSpace
+	if space <= 0 {
+		for _, files := range sp.ClusterFlist {
+			for _, id := range files {
+				space = max(space, id+1)
+			}
+		}
+	}
+	return max(space, used+need)
+}
+
+// LocalSearchPadded 解密服务器返回的结果并按填充模式填充：每个分区的结果先填充到档位，
+// 整个结果集再填充到档位，真实与虚拟文件 ID 随机打乱后作为取回文件的标识符列表返回。
+// 虚拟文件 ID 从真实文件 ID 的取值范围中随机选取并避开本次结果中的 ID，服务器无法按取值区分；
+// 同时返回虚拟文件 ID 集合，由 DummySet.Filter 在客户端去掉。PadNone 模式下等同于 LocalSearch，集合为空
+func (sp *Client) LocalSearchPadded(query *QueryContext, resp *SearchResponse) ([]int, DummySet, error) {
+	if sp.Padding == PadNone {
+		ids, err := sp.LocalSearch(query, resp)
+		return ids, nil, err
+	}
+	parts, err := sp.localSearchParts(query, resp)
+	if err != nil {
+		return nil, nil, err
+	}
+	sizes := make([]int, len(parts))
+	used := map[int]struct{}{}
+	total := 0
+	for i, part := range parts {
+		if sizes[i], err = sp.paddedSize(len(part)); err != nil {
+			return nil, nil, err
+		}
+		for _, id := range part {
+			used[id] = struct{}{}
+		}
+		total += sizes[i]
+	}
+	size, err := sp.paddedSize(total)
+	if err != nil {
+		return nil, nil, err
+	}
+	space := sp.dummySpace(len(used), size-len(used))
+	dummies := DummySet{}
+	padded := []int{}
+	pad := func(n int) {
+		for ; n > 0; n-- {
+			id := rand.IntN(space)
+			for _, ok := used[id]; ok; _, ok = used[id] {
+				id = rand.IntN(space)
+			}
+			used[id] = struct{}{}
+			dummies[id] = struct{}{}
+			padded = append(padded, id)
+		}
+	}
+	for i, part := range parts {
+		padded = append(padded, part...)
+		pad(sizes[i] - len(part))
+	}
+	pad(size - len(padded))
+	rand.Shuffle(len(padded), func(i, j int) { padded[i], padded[j] = padded[j], padded[i] })
+	return padded, dummies, nil
+}
@@ -0,0 +1,116 @@
+package OurScheme
+
+// packPartition 按 BuildIndex 的规则将关键词依次装入文件数小于 L 的分区，返回每个分区的关键词个数
+func (sp *Client) packPartition(volumes []int) []int {
+	groups := []int{}
+	size, count := 0, 0
+	for _, volume := range volumes {
+		if count > 0 && size+volume >= sp.L {
+			groups = append(groups, count)
+			size, count = 0, 0
+		}
+		size += volume
+		count++
+	}
+	if count > 0 {
+		groups = append(groups, count)
+	}
+	return groups
+}
+
+// splitPartition 分区 p 的文件数达到 L 时按关键词边界拆分，返回拆分后的分区个数（未拆分时为 1）
+func (sp *Client) splitPartition(p int) (int, error) {
+	if len(sp.ClusterFlist[p]) < sp.L {
+		return 1, nil
+	}
+	groups := sp.packPartition(sp.ClusterVlist[p])
+	if len(groups) <= 1 {
+		return 1, nil
+	}
+	klists := make([][]string, 0, len(groups))
+	vlists := make([][]int, 0, len(groups))
+	flists := make([][]int, 0, len(groups))
+	k, start := 0, 0
+	for _, count := range groups {
+		size := 0
+		for _, volume := range sp.ClusterVlist[p][k : k+count] {
+			size += volume
+		}
+		klists = append(klists, append([]string{}, sp.ClusterKlist[p][k:k+count]...))
+		vlists = append(vlists, append([]int{}, sp.ClusterVlist[p][k:k+count]...))
+		flists = append(flists, append([]int{}, sp.ClusterFlist[p][start:start+size]...))
+		k += count
+		start += size
+	}
+	if err := sp.replacePartitions(p, 1, klists, vlists, flists); err != nil {
+		return 0, err
+	}
+	return len(groups), nil
+}
+
+// mergePartition 分区 p 的文件数低于 MergeBelow 时，与合并后文件数仍小于 L 的较小相邻分区合并，
+// 返回合并后分区的位置、左侧分区原有的关键词个数以及是否发生了合并
+func (sp *Client) mergePartition(p int) (int, int, bool, error) {
+	if sp.MergeBelow <= 0 || len(sp.ClusterFlist[p]) >= sp.MergeBelow {
+		return p, 0, false, nil
+	}
+	q := -1
+	for _, n := range []int{p - 1, p + 1} {
+		if n < 0 || n >= len(sp.ClusterFlist) || len(sp.ClusterFlist[p])+len(sp.ClusterFlist[n]) >= sp.L {
+			continue
+		}
+		if q < 0 || len(sp.ClusterFlist[n]) < len(sp.ClusterFlist[q]) {
+			q = n
+		}
+	}
+	if q < 0 {
+		return p, 0, false, nil
+	}
+	left := min(p, q)
+	leftLen := len(sp.ClusterKlist[left])
+	err := sp.replacePartitions(left, 2,
+		[][]string{append(append([]string{}, sp.ClusterKlist[left]...), sp.ClusterKlist[left+1]...)},
+		[][]int{append(append([]int{}, sp.ClusterVlist[left]...), sp.ClusterVlist[left+1]...)},
+		[][]int{append(append([]int{}, sp.ClusterFlist[left]...), sp.ClusterFlist[left+1]...)},
+	)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	return left, leftLen, true, nil
+}
+
+// replacePartitions 用新的分区替换从 p 开始的 n 个分区，分区边界树随之增量更新，其后分区的红黑树随位置平移
+func (sp *Client) replacePartitions(p, n int, klists [][]string, vlists [][]int, flists [][]int) error {
+	bounds := make([][2]int64, len(klists))
+	for k, klist := range klists {
+		b, err := sp.partitionBounds(klist)
+		if err != nil {
+			return err
+		}
+		bounds[k] = b
+	}
+	for k := 0; k < n; k++ {
+		sp.boundaries.Remove(p)
+	}
+	for k, b := range bounds {
+		sp.boundaries.Insert(p+k, b)
+	}
+
+	tail := p + n
+	sp.ClusterKlist = append(sp.ClusterKlist[:p], append(klists, sp.ClusterKlist[tail:]...)...)
+	sp.ClusterVlist = append(sp.ClusterVlist[:p], append(vlists, sp.ClusterVlist[tail:]...)...)
+	sp.ClusterFlist = append(sp.ClusterFlist[:p], append(flists, sp.ClusterFlist[tail:]...)...)
+
+	// 被替换的分区的红黑树在下次更新时重建
+	trees := make(map[int]*RBTree, len(sp.clusterTrees))
+	for q, tree := range sp.clusterTrees {
+		switch {
+		case q < p:
+			trees[q] = tree
+		case q >= tail:
+			trees[q-n+len(klists)] = tree
+		}
+	}
+	sp.clusterTrees = trees
+	return nil
+}
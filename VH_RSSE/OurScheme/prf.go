@@ -0,0 +1,80 @@
+package OurScheme
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+// 伪随机函数的域分隔标签，保证搜索 token 与 OTP 密钥相互独立
+var (
+	tokenLabel = []byte("OurScheme/token")
+	otpLabel   = []byte("OurScheme/otp")
+
+	versionTokenLabel = []byte("OurScheme/version-token")
+	versionOTPLabel   = []byte("OurScheme/version-otp")
+
+	attrTokenLabel = []byte("OurScheme/attr-token")
+	attrOTPLabel   = []byte("OurScheme/attr-otp")
+
+	macLabel = []byte("OurScheme/mac")
+)
+
+// HMACPRF 是伪随机函数的实现（使用 HMAC-SHA256）
+func HMACPRF(key []byte) func([]byte) []byte {
+	return func(data []byte) []byte {
+		h := hmac.New(sha256.New, key)
+		h.Write(data)
+		return h.Sum(nil)
+	}
+}
+
+// prf 在密钥 sp.Key 下计算带域分隔标签的伪随机函数
+func (sp *Client) prf(label []byte, keyword string) []byte {
+	data := make([]byte, 0, len(label)+1+len(keyword))
+	data = append(data, label...)
+	data = append(data, 0)
+	data = append(data, keyword...)
+	return HMACPRF(sp.Key)(data)
+}
+
+// versionedPRF 关键词当前版本的伪随机函数值：版本 0（BuildIndex 写入）使用 label，
+// 前向安全模式下每次更新后版本加一，使用 versionLabel 并以 "版本\x00关键词" 为输入
+func (sp *Client) versionedPRF(label, versionLabel []byte, keyword string) []byte {
+	version := sp.Versions[keyword]
+	if version == 0 {
+		return sp.prf(label, keyword)
+	}
+	return sp.prf(versionLabel, strconv.Itoa(version)+"\x00"+keyword)
+}
+
+// searchToken 关键词当前版本在 EDB 中的地址，服务器只能看到该值
+func (sp *Client) searchToken(keyword string) string {
+	return hex.EncodeToString(sp.versionedPRF(tokenLabel, versionTokenLabel, keyword))
+}
+
+// otpKey 关键词当前版本位图的加密密钥，只保存在客户端
+func (sp *Client) otpKey(keyword string) []byte {
+	return sp.versionedPRF(otpLabel, versionOTPLabel, keyword)
+}
+
+// attrKeyword 分区 p 内属性值 value 对应的 PRF 输入
+func attrKeyword(p int, value string) string {
+	return strconv.Itoa(p) + "\x00" + value
+}
+
+// attrToken 分区 p 内属性值 value 的位图在 EDB 中的地址
+func (sp *Client) attrToken(p int, value string) string {
+	return hex.EncodeToString(sp.prf(attrTokenLabel, attrKeyword(p, value)))
+}
+
+// attrOTPKey 分区 p 内属性值 value 的位图加密密钥
+func (sp *Client) attrOTPKey(p int, value string) []byte {
+	return sp.prf(attrOTPLabel, attrKeyword(p, value))
+}
+
+// entryMAC 条目的 MAC：绑定 token、关键词条目的写入次数与密文，服务器无法替换或重放旧条目
+func (sp *Client) entryMAC(token string, counter int, value []byte) []byte {
+	return sp.prf(macLabel, token+"\x00"+strconv.Itoa(counter)+"\x00"+string(value))
+}
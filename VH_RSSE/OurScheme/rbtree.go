@@ -0,0 +1,319 @@
+package OurScheme
+
+// 红黑树节点定义：按 (Key, Value) 排序，Key 为关键词编码后的数值，Value 为文件 ID
+type RBNode struct {
+	Key    int64
+	Value  int
+	Color  bool // true: 红色, false: 黑色
+	Left   *RBNode
+	Right  *RBNode
+	Parent *RBNode
+}
+
+// 红黑树结构定义：作为分区的有序结构，中序遍历即为分区文件列表
+type RBTree struct {
+	Root *RBNode
+	NIL  *RBNode // 哨兵节点（简化边界处理）
+	Size int     // 节点数量
+}
+
+// 初始化红黑树（保持原有逻辑）
+func NewRBTree() *RBTree {
+	nilNode := &RBNode{Color: false} // 哨兵节点为黑色
+	return &RBTree{
+		NIL:  nilNode,
+		Root: nilNode,
+	}
+}
+
+// less 按 (Key, Value) 比较两个节点的顺序
+func less(key1 int64, value1 int, key2 int64, value2 int) bool {
+	return key1 < key2 || (key1 == key2 && value1 < value2)
+}
+
+// find 查找 (key, value) 对应的节点，不存在时返回哨兵节点
+func (t *RBTree) find(key int64, value int) *RBNode {
+	current := t.Root
+	for current != t.NIL {
+		if current.Key == key && current.Value == value {
+			return current
+		}
+		if less(key, value, current.Key, current.Value) {
+			current = current.Left
+		} else {
+			current = current.Right
+		}
+	}
+	return t.NIL
+}
+
+// Contains 判断 (key, value) 是否在树中
+func (t *RBTree) Contains(key int64, value int) bool {
+	return t.find(key, value) != t.NIL
+}
+
+// 红黑树插入函数：(key, value) 已存在时不重复插入并返回 false
+func (t *RBTree) Insert(key int64, value int) bool {
+	newNode := &RBNode{
+		Key:   key,
+		Value: value,
+		Color: true, // 新节点默认为红色
+		Left:  t.NIL,
+		Right: t.NIL,
+	}
+
+	// 标准BST插入
+	var parent *RBNode = nil
+	current := t.Root
+	for current != t.NIL {
+		if current.Key == key && current.Value == value {
+			return false
+		}
+		parent = current
+		if less(key, value, current.Key, current.Value) {
+			current = current.Left
+		} else {
+			current = current.Right
+		}
+	}
+	t.Size++
+	newNode.Parent = parent
+	if parent == nil {
+		t.Root = newNode // 树为空时，新节点为根
+	} else if less(key, value, parent.Key, parent.Value) {
+		parent.Left = newNode
+	} else {
+		parent.Right = newNode
+	}
+
+	// 根节点特殊处理（设为黑色）
+	if newNode.Parent == nil {
+		newNode.Color = false
+		return true
+	}
+	// 父节点为根节点时无需调整
+	if newNode.Parent.Parent == nil {
+		return true
+	}
+
+	// 插入后修复红黑树性质
+	t.fixInsert(newNode)
+	return true
+}
+
+// 插入后修复红黑树（保持原有逻辑）
+func (t *RBTree) fixInsert(z *RBNode) {
+	for z.Parent.Color {
+		if z.Parent == z.Parent.Parent.Left {
+			y := z.Parent.Parent.Right // 叔节点
+			if y.Color {
+				// 情况1：叔节点为红色，只需变色
+				z.Parent.Color = false
+				y.Color = false
+				z.Parent.Parent.Color = true
+				z = z.Parent.Parent
+			} else {
+				if z == z.Parent.Right {
+					// 情况2：叔节点为黑色，当前节点为右孩子，先左旋
+					z = z.Parent
+					t.leftRotate(z)
+				}
+				// 情况3：叔节点为黑色，当前节点为左孩子，右旋+变色
+				z.Parent.Color = false
+				z.Parent.Parent.Color = true
+				t.rightRotate(z.Parent.Parent)
+			}
+		} else {
+			// 镜像情况（父节点为右孩子）
+			y := z.Parent.Parent.Left
+			if y.Color {
+				z.Parent.Color = false
+				y.Color = false
+				z.Parent.Parent.Color = true
+				z = z.Parent.Parent
+			} else {
+				if z == z.Parent.Left {
+					z = z.Parent
+					t.rightRotate(z)
+				}
+				z.Parent.Color = false
+				z.Parent.Parent.Color = true
+				t.leftRotate(z.Parent.Parent)
+			}
+		}
+		if z == t.Root {
+			break
+		}
+	}
+	t.Root.Color = false // 确保根节点为黑色
+}
+
+// Delete 删除 (key, value)，不存在时返回 false
+func (t *RBTree) Delete(key int64, value int) bool {
+	z := t.find(key, value)
+	if z == t.NIL {
+		return false
+	}
+	t.Size--
+
+	y := z
+	yColor := y.Color
+	var x *RBNode
+	if z.Left == t.NIL {
+		x = z.Right
+		t.transplant(z, z.Right)
+	} else if z.Right == t.NIL {
+		x = z.Left
+		t.transplant(z, z.Left)
+	} else {
+		// 有两个孩子时用后继节点替换
+		y = z.Right
+		for y.Left != t.NIL {
+			y = y.Left
+		}
+		yColor = y.Color
+		x = y.Right
+		if y.Parent == z {
+			x.Parent = y
+		} else {
+			t.transplant(y, y.Right)
+			y.Right = z.Right
+			y.Right.Parent = y
+		}
+		t.transplant(z, y)
+		y.Left = z.Left
+		y.Left.Parent = y
+		y.Color = z.Color
+	}
+	// 删除的是黑色节点时修复红黑树性质
+	if !yColor {
+		t.fixDelete(x)
+	}
+	t.NIL.Parent = nil
+	return true
+}
+
+// transplant 用以 v 为根的子树替换以 u 为根的子树
+func (t *RBTree) transplant(u, v *RBNode) {
+	if u.Parent == nil {
+		t.Root = v
+	} else if u == u.Parent.Left {
+		u.Parent.Left = v
+	} else {
+		u.Parent.Right = v
+	}
+	v.Parent = u.Parent
+}
+
+// 删除后修复红黑树
+func (t *RBTree) fixDelete(x *RBNode) {
+	for x != t.Root && !x.Color {
+		if x == x.Parent.Left {
+			w := x.Parent.Right // 兄弟节点
+			if w.Color {
+				// 情况1：兄弟节点为红色，旋转后转为其他情况
+				w.Color = false
+				x.Parent.Color = true
+				t.leftRotate(x.Parent)
+				w = x.Parent.Right
+			}
+			if !w.Left.Color && !w.Right.Color {
+				// 情况2：兄弟节点的两个孩子均为黑色，兄弟变红后上移
+				w.Color = true
+				x = x.Parent
+			} else {
+				if !w.Right.Color {
+					// 情况3：兄弟节点的右孩子为黑色，先右旋兄弟节点
+					w.Left.Color = false
+					w.Color = true
+					t.rightRotate(w)
+					w = x.Parent.Right
+				}
+				// 情况4：兄弟节点的右孩子为红色，左旋+变色
+				w.Color = x.Parent.Color
+				x.Parent.Color = false
+				w.Right.Color = false
+				t.leftRotate(x.Parent)
+				x = t.Root
+			}
+		} else {
+			// 镜像情况（x 为右孩子）
+			w := x.Parent.Left
+			if w.Color {
+				w.Color = false
+				x.Parent.Color = true
+				t.rightRotate(x.Parent)
+				w = x.Parent.Left
+			}
+			if !w.Left.Color && !w.Right.Color {
+				w.Color = true
+				x = x.Parent
+			} else {
+				if !w.Left.Color {
+					w.Right.Color = false
+					w.Color = true
+					t.leftRotate(w)
+					w = x.Parent.Left
+				}
+				w.Color = x.Parent.Color
+				x.Parent.Color = false
+				w.Left.Color = false
+				t.rightRotate(x.Parent)
+				x = t.Root
+			}
+		}
+	}
+	x.Color = false
+}
+
+// 左旋操作（保持原有逻辑）
+func (t *RBTree) leftRotate(x *RBNode) {
+	y := x.Right
+	x.Right = y.Left
+	if y.Left != t.NIL {
+		y.Left.Parent = x
+	}
+	y.Parent = x.Parent
+	if x.Parent == nil {
+		t.Root = y
+	} else if x == x.Parent.Left {
+		x.Parent.Left = y
+	} else {
+		x.Parent.Right = y
+	}
+	y.Left = x
+	x.Parent = y
+}
+
+// 右旋操作（保持原有逻辑）
+func (t *RBTree) rightRotate(y *RBNode) {
+	x := y.Left
+	y.Left = x.Right
+	if x.Right != t.NIL {
+		x.Right.Parent = y
+	}
+	x.Parent = y.Parent
+	if y.Parent == nil {
+		t.Root = x
+	} else if y == y.Parent.Right {
+		y.Parent.Right = x
+	} else {
+		y.Parent.Left = x
+	}
+	x.Right = y
+	y.Parent = x
+}
+
+// InOrder 按 (Key, Value) 升序遍历所有节点
+func (t *RBTree) InOrder(visit func(key int64, value int)) {
+	var traverse func(node *RBNode)
+	traverse = func(node *RBNode) {
+		if node == t.NIL {
+			return
+		}
+		traverse(node.Left)
+		visit(node.Key, node.Value)
+		traverse(node.Right)
+	}
+	traverse(t.Root)
+}
@@ -0,0 +1,125 @@
+package OurScheme
+
+import (
+	"EfficientAndLowStroageSSE/storage"
+	"fmt"
+	"io"
+	"log"
+)
+
+// Server 服务器状态：只持有加密数据库
+type Server struct {
+	EDB  map[string][]byte // 加密数据库
+	MACs map[string][]byte // 可验证模式下条目的 MAC，服务器只负责保存并随搜索结果返回
+	L    int               // 位图长度，持久化时用于校验参数
+}
+
+// NewServer 初始化位图长度为 L 的服务器
+func NewServer(L int) *Server {
+	return &Server{
+		EDB:  make(map[string][]byte),
+		MACs: make(map[string][]byte),
+		L:    L,
+	}
+}
+
+// ApplyUpdate 将客户端上传的加密条目写入 EDB，任一条目长度与 L 不符时不写入任何条目并返回错误
+func (s *Server) ApplyUpdate(req *UpdateRequest) error {
+	if req == nil {
+		return fmt.Errorf("更新消息为空")
+	}
+	entrySize := nonceSize + bitmapSize(s.L)
+	for token, value := range req.Entries {
+		if len(value) != entrySize {
+			return fmt.Errorf("条目 %x 长度为 %d，期望 %d", token, len(value), entrySize)
+		}
+	}
+	for token, value := range req.Entries {
+		s.EDB[token] = value
+	}
+	for token, mac := range req.MACs {
+		s.MACs[token] = mac
+	}
+	return nil
+}
+
+// Search 根据查询请求中的 token 返回对应的加密位图，保存有 MAC 时一并返回作为证明
+func (s *Server) Search(req *SearchRequest) *SearchResponse {
+	searchResult := [][]byte{}
+	var proofs [][]byte
+	for _, token := range req.Tokens {
+		// 从加密数据库中获取与 token 对应的加密位图
+		if value, ok := s.EDB[token]; ok {
+			searchResult = append(searchResult, value)
+			if len(s.MACs) > 0 {
+				proofs = append(proofs, s.MACs[token])
+			}
+		} else {
+			log.Printf("Token not found in EDB: %v", token)
+		}
+	}
+	return &SearchResponse{Results: searchResult, Proofs: proofs}
+}
+
+// Save 将 EDB 按 storage 格式写出，服务器重启后可通过 Load 恢复而无需客户端重新 BuildIndex
+func (s *Server) Save(w io.Writer) error {
+	sw, err := storage.NewWriter(w, storage.Header{
+		Scheme: storage.SchemeOurScheme,
+		Param:  uint64(s.L),
+		Count:  uint64(len(s.EDB)),
+	})
+	if err != nil {
+		return err
+	}
+	for token, value := range s.EDB {
+		if err := sw.WriteField([]byte(token)); err != nil {
+			return err
+		}
+		if err := sw.WriteField(value); err != nil {
+			return err
+		}
+		// 条目的 MAC，未启用可验证模式时为空
+		if err := sw.WriteField(s.MACs[token]); err != nil {
+			return err
+		}
+	}
+	return sw.Flush()
+}
+
+// Load 从 Save 写出的数据中恢复 EDB，文件中的 L 与服务器不一致时返回错误
+func (s *Server) Load(r io.Reader) error {
+	sr, err := storage.NewReader(r, storage.SchemeOurScheme)
+	if err != nil {
+		return err
+	}
+	if sr.Header.Param != uint64(s.L) {
+		return fmt.Errorf("EDB 参数不匹配: 文件中 L=%d，服务器 L=%d", sr.Header.Param, s.L)
+	}
+	entrySize := nonceSize + bitmapSize(s.L)
+	EDB := make(map[string][]byte, sr.Header.Count)
+	MACs := make(map[string][]byte)
+	for i := uint64(0); i < sr.Header.Count; i++ {
+		token, err := sr.ReadField()
+		if err != nil {
+			return fmt.Errorf("读取第 %d 个条目失败: %v", i, err)
+		}
+		value, err := sr.ReadField()
+		if err != nil {
+			return fmt.Errorf("读取第 %d 个条目失败: %v", i, err)
+		}
+		mac, err := sr.ReadField()
+		if err != nil {
+			return fmt.Errorf("读取第 %d 个条目失败: %v", i, err)
+		}
+		if len(value) != entrySize {
+			return fmt.Errorf("第 %d 个条目长度为 %d，期望 %d", i, len(value), entrySize)
+		}
+		EDB[string(token)] = value
+		if len(mac) > 0 {
+			MACs[string(token)] = mac
+		}
+	}
+	s.EDB = EDB
+	s.MACs = MACs
+	return nil
+}
@@ -0,0 +1,83 @@
+package OurScheme
+
+import (
+	"EfficientAndLowStroageSSE/spatial"
+	"fmt"
+	"sort"
+)
+
+// BoxQueries 将矩形查询分解为一维范围查询：索引的关键词须为 grid 生成的 Morton 码，
+// 分解得到的 Morton 码区间收缩到区间内实际存在的关键词，相邻区间之间没有关键词时合并为一次查询
+func (sp *Client) BoxQueries(grid spatial.Grid, box spatial.Box) ([][2]string, error) {
+	ranges, err := grid.Decompose(box)
+	if err != nil {
+		return nil, err
+	}
+	keywords := []string{}
+	for _, klist := range sp.ClusterKlist {
+		keywords = append(keywords, klist...)
+	}
+	values, err := sp.Codec.EncodeAll(keywords)
+	if err != nil {
+		return nil, err
+	}
+
+	queries := [][2]string{}
+	last := -2 // 上一次查询右边界关键词的下标
+	for _, r := range ranges {
+		i := sort.Search(len(values), func(k int) bool { return uint64(values[k]) >= r.Lo })
+		j := sort.Search(len(values), func(k int) bool { return uint64(values[k]) > r.Hi }) - 1
+		if i > j {
+			continue
+		}
+		if i == last+1 {
+			queries[len(queries)-1][1] = keywords[j]
+		} else {
+			queries = append(queries, [2]string{keywords[i], keywords[j]})
+		}
+		last = j
+	}
+	return queries, nil
+}
+
+// SearchBox 执行矩形查询，返回与矩形相交的网格单元中的全部文件 ID。
+// 索引只记录文件所在的单元，矩形边界上的单元只有一部分落在矩形内，其中位于矩形外的文件同样会返回，
+// 需要精确结果时使用 SearchBoxExact
+func (sp *OurScheme) SearchBox(grid spatial.Grid, box spatial.Box) ([]int, error) {
+	queries, err := sp.BoxQueries(grid, box)
+	if err != nil {
+		return nil, err
+	}
+	result := []int{}
+	for _, queryRange := range queries {
+		query, err := sp.GenToken(queryRange)
+		if err != nil {
+			return nil, err
+		}
+		ids, err := sp.Client.LocalSearch(query, sp.Server.Search(query.Request()))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, ids...)
+	}
+	return result, nil
+}
+
+// SearchBoxExact 执行矩形查询，并按客户端保存的文件坐标 points 去掉边界单元中位于矩形外的文件
+func (sp *OurScheme) SearchBoxExact(grid spatial.Grid, box spatial.Box, points map[int]spatial.Point) ([]int, error) {
+	ids, err := sp.SearchBox(grid, box)
+	if err != nil {
+		return nil, err
+	}
+	result := []int{}
+	for _, id := range ids {
+		p, exists := points[id]
+		if !exists {
+			return nil, fmt.Errorf("文件 %d 没有坐标，无法判断是否在矩形内", id)
+		}
+		if box.Contains(p) {
+			result = append(result, id)
+		}
+	}
+	return result, nil
+}
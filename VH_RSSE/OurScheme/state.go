@@ -0,0 +1,104 @@
+package OurScheme
+
+import (
+	"EfficientAndLowStroageSSE/codec"
+	"EfficientAndLowStroageSSE/storage"
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"io"
+)
+
+// clientState 客户端状态中需要持久化的部分，H1/H2 为固定的 SHA-256，导入时重新生成
+type clientState struct {
+	L            int
+	Key          []byte
+	ClusterFlist [][]int
+	ClusterKlist [][]string
+	ClusterVlist [][]int
+	BsLength     int
+	Codec        codec.Codec
+	MergeBelow   int
+
+	ForwardPrivate bool
+	Versions       map[string]int
+
+	Verifiable bool
+	Counters   map[string]int
+
+	Padding    Padding
+	BucketSize int
+	IDSpace    int
+
+	Attributes map[int][]string
+	AttrValues [][]string
+}
+
+// Export 将客户端状态用口令派生的密钥加密并认证后写出，用于备份或迁移客户端
+func (sp *Client) Export(w io.Writer, password []byte) error {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(clientState{
+		L:            sp.L,
+		Key:          sp.Key,
+		ClusterFlist: sp.ClusterFlist,
+		ClusterKlist: sp.ClusterKlist,
+		ClusterVlist: sp.ClusterVlist,
+		BsLength:     sp.BsLength,
+		Codec:        sp.Codec,
+		MergeBelow:   sp.MergeBelow,
+
+		ForwardPrivate: sp.ForwardPrivate,
+		Versions:       sp.Versions,
+
+		Verifiable: sp.Verifiable,
+		Counters:   sp.Counters,
+
+		Padding:    sp.Padding,
+		BucketSize: sp.BucketSize,
+		IDSpace:    sp.IDSpace,
+
+		Attributes: sp.Attributes,
+		AttrValues: sp.attrValues,
+	})
+	if err != nil {
+		return fmt.Errorf("序列化客户端状态失败: %v", err)
+	}
+	return storage.Seal(w, password, storage.SchemeOurScheme, buf.Bytes())
+}
+
+// ImportClient 读取 Export 写出的客户端状态，口令错误或数据被篡改时返回错误
+func ImportClient(r io.Reader, password []byte) (*Client, error) {
+	plaintext, err := storage.Open(r, password, storage.SchemeOurScheme)
+	if err != nil {
+		return nil, err
+	}
+	var state clientState
+	if err := gob.NewDecoder(bytes.NewReader(plaintext)).Decode(&state); err != nil {
+		return nil, fmt.Errorf("解析客户端状态失败: %v", err)
+	}
+	sp := NewClient(state.L)
+	sp.Key = state.Key
+	sp.ClusterFlist = state.ClusterFlist
+	sp.ClusterKlist = state.ClusterKlist
+	sp.ClusterVlist = state.ClusterVlist
+	sp.BsLength = state.BsLength
+	sp.Codec = state.Codec
+	sp.MergeBelow = state.MergeBelow
+	sp.ForwardPrivate = state.ForwardPrivate
+	if state.Versions != nil {
+		sp.Versions = state.Versions
+	}
+	sp.Verifiable = state.Verifiable
+	if state.Counters != nil {
+		sp.Counters = state.Counters
+	}
+	sp.Padding = state.Padding
+	sp.BucketSize = state.BucketSize
+	sp.IDSpace = state.IDSpace
+	sp.Attributes = state.Attributes
+	sp.attrValues = state.AttrValues
+	if err := sp.buildBoundaryTree(sp.ClusterKlist); err != nil {
+		return nil, err
+	}
+	return sp, nil
+}
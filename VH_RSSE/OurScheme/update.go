@@ -0,0 +1,304 @@
+package OurScheme
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// Op 更新操作类型
+type Op int
+
+const (
+	OpAdd Op = iota // 向关键词添加文件
+	OpDel           // 从关键词删除文件
+)
+
+// String 返回操作名称
+func (op Op) String() string {
+	switch op {
+	case OpAdd:
+		return "add"
+	case OpDel:
+		return "del"
+	default:
+		return fmt.Sprintf("Op(%d)", int(op))
+	}
+}
+
+// UpdateOp 按操作类型向关键词 w 添加或删除文件，返回发往服务器的更新消息
+func (sp *Client) UpdateOp(op Op, w string, docID []*big.Int) (*UpdateRequest, error) {
+	switch op {
+	case OpAdd:
+		return sp.Update(w, docID)
+	case OpDel:
+		return sp.Delete(w, docID)
+	default:
+		return nil, fmt.Errorf("未知的更新操作 %v", op)
+	}
+}
+
+// Update 向关键词 w 添加文件：文件按 (关键词, 文件 ID) 插入所在分区的红黑树，分区文件列表由其中序遍历得到，
+// 并重新加密分区内从 w 开始的所有前缀位图；w 尚未建立索引时作为新关键词加入其所在分区。
+// 分区文件数达到 L 时按 BuildIndex 的规则拆分，拆分出的分区中的关键词全部重新加密；
+// 建立了属性索引时同时重新生成受影响分区的属性位图
+func (sp *Client) Update(w string, docID []*big.Int) (*UpdateRequest, error) {
+	key, err := sp.Codec.Encode(w)
+	if err != nil {
+		return nil, fmt.Errorf("无法将关键词[%s]转换为整数: %v", w, err)
+	}
+	p, err := sp.locatePartition(key)
+	if err != nil {
+		return nil, fmt.Errorf("定位关键词[%s]分区失败: %v", w, err)
+	}
+	tree, err := sp.partitionTree(p)
+	if err != nil {
+		return nil, err
+	}
+	values, err := sp.Codec.EncodeAll(sp.ClusterKlist[p])
+	if err != nil {
+		return nil, err
+	}
+	i := sort.Search(len(values), func(k int) bool { return values[k] >= key })
+	isNew := i == len(values) || values[i] != key
+
+	// 去掉已在分区中的文件，单个关键词的文件数不能超过位图长度 L
+	added := make(map[int]bool, len(docID))
+	for _, id := range docID {
+		if idInt := int(id.Int64()); !tree.Contains(key, idInt) {
+			added[idInt] = true
+		}
+	}
+	volume := len(added)
+	if !isNew {
+		volume += sp.ClusterVlist[p][i]
+	}
+	if volume > sp.L {
+		return nil, fmt.Errorf("关键词[%s]的文件数 %d 超出位图长度 L=%d", w, volume, sp.L)
+	}
+
+	req := NewUpdateRequest()
+	if len(added) == 0 && !isNew {
+		return req, nil
+	}
+	if isNew {
+		// 新关键词按数值顺序插入分区关键词列表
+		sp.ClusterKlist[p] = append(sp.ClusterKlist[p][:i], append([]string{w}, sp.ClusterKlist[p][i:]...)...)
+		sp.ClusterVlist[p] = append(sp.ClusterVlist[p][:i], append([]int{0}, sp.ClusterVlist[p][i:]...)...)
+	}
+	for id := range added {
+		tree.Insert(key, id)
+	}
+	if err := sp.syncPartition(p); err != nil {
+		return nil, err
+	}
+
+	// 第一个分区的前缀位图从 w 开始变化，拆分出的其余分区全部重新加密
+	n, err := sp.splitPartition(p)
+	if err != nil {
+		return nil, err
+	}
+	for q := p; q < p+n; q++ {
+		from := 0
+		if q == p {
+			from = min(i, len(sp.ClusterKlist[p]))
+		}
+		if err := sp.refreshPartition(q, from, req); err != nil {
+			return nil, err
+		}
+	}
+	// 未拆分但新关键词成为分区的第一个或最后一个关键词时，更新该分区的边界
+	if n == 1 && isNew && (i == 0 || i == len(sp.ClusterKlist[p])-1) {
+		bounds, err := sp.partitionBounds(sp.ClusterKlist[p])
+		if err != nil {
+			return nil, err
+		}
+		sp.boundaries.Set(p, bounds)
+	}
+	// 分区内文件的位置发生变化，重新生成属性位图
+	partitions := make([]int, n)
+	for k := range partitions {
+		partitions[k] = p + k
+	}
+	if err := sp.refreshAttributes(partitions, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// Delete 从关键词 w 中删除文件：将这些文件从所在分区的红黑树中移除，
+// 并重新加密该分区内从 w 开始的所有前缀位图；不属于 w 的文件 ID 被忽略。
+// 设置了 MergeBelow 时，文件数低于该值的分区与相邻分区合并
+func (sp *Client) Delete(w string, docID []*big.Int) (*UpdateRequest, error) {
+	p, i, err := sp.locateKeyword(w)
+	if err != nil {
+		return nil, err
+	}
+	key, err := sp.Codec.Encode(w)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := sp.partitionTree(p)
+	if err != nil {
+		return nil, err
+	}
+
+	removed := 0
+	for _, id := range docID {
+		if tree.Delete(key, int(id.Int64())) {
+			removed++
+		}
+	}
+	req := NewUpdateRequest()
+	if removed == 0 { // 没有需要删除的文件
+		return req, nil
+	}
+	if err := sp.syncPartition(p); err != nil {
+		return nil, err
+	}
+
+	// 合并后右侧分区的关键词全部重新加密
+	q, leftLen, merged, err := sp.mergePartition(p)
+	if err != nil {
+		return nil, err
+	}
+	from := i
+	if merged && q != p {
+		from = leftLen
+	}
+	if err := sp.refreshPartition(q, from, req); err != nil {
+		return nil, err
+	}
+	if err := sp.refreshAttributes([]int{q}, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// locatePartition 返回插入数值 key 的分区：落在索引范围内时沿分区边界树查找，小于最小关键词时为第一个分区，大于最大关键词时为最后一个分区
+func (sp *Client) locatePartition(key int64) (int, error) {
+	if sp.boundaries == nil || sp.boundaries.Len() == 0 {
+		return 0, fmt.Errorf("索引为空")
+	}
+	var p int
+	switch last := sp.boundaries.Len() - 1; {
+	case key < sp.boundaries.Bounds(0)[0]:
+		p = 0
+	case key > sp.boundaries.Bounds(last)[1]:
+		p = last
+	default:
+		var err error
+		if p, err = sp.searchTreeValue(key); err != nil {
+			return 0, err
+		}
+	}
+	if p >= len(sp.ClusterKlist) || p >= len(sp.ClusterFlist) || p >= len(sp.ClusterVlist) {
+		return 0, fmt.Errorf("分区索引 %d 无效，超出范围[0,%d]", p, len(sp.ClusterKlist)-1)
+	}
+	return p, nil
+}
+
+// locateKeyword 返回已建立索引的关键词所在的分区及其在分区关键词列表中的下标
+func (sp *Client) locateKeyword(w string) (int, int, error) {
+	p, err := sp.searchTree(w)
+	if err != nil {
+		return 0, 0, fmt.Errorf("定位关键词[%s]分区失败: %v", w, err)
+	}
+	if p < 0 || p >= len(sp.ClusterKlist) || p >= len(sp.ClusterFlist) {
+		return 0, 0, fmt.Errorf("关键词[%s]分区索引 %d 无效，超出范围[0,%d]", w, p, len(sp.ClusterKlist)-1)
+	}
+	if p >= len(sp.ClusterVlist) || len(sp.ClusterVlist[p]) != len(sp.ClusterKlist[p]) {
+		return 0, 0, fmt.Errorf("客户端状态缺少分区 %d 的关键词文件数量", p)
+	}
+	i := indexOf(sp.ClusterKlist[p], w)
+	if i < 0 {
+		return 0, 0, fmt.Errorf("关键词[%s]不在索引中", w)
+	}
+	return p, i, nil
+}
+
+// partitionTree 返回分区 p 的红黑树，首次更新该分区时由分区文件列表和关键词文件数量重建
+func (sp *Client) partitionTree(p int) (*RBTree, error) {
+	if tree, ok := sp.clusterTrees[p]; ok {
+		return tree, nil
+	}
+	values, err := sp.Codec.EncodeAll(sp.ClusterKlist[p])
+	if err != nil {
+		return nil, err
+	}
+	tree := NewRBTree()
+	start := 0
+	for k, volume := range sp.ClusterVlist[p] {
+		for _, id := range sp.ClusterFlist[p][start : start+volume] {
+			tree.Insert(values[k], id)
+		}
+		start += volume
+	}
+	if sp.clusterTrees == nil {
+		sp.clusterTrees = make(map[int]*RBTree)
+	}
+	sp.clusterTrees[p] = tree
+	return tree, nil
+}
+
+// syncPartition 按红黑树的中序遍历重新生成分区 p 的文件列表和关键词文件数量
+func (sp *Client) syncPartition(p int) error {
+	tree := sp.clusterTrees[p]
+	values, err := sp.Codec.EncodeAll(sp.ClusterKlist[p])
+	if err != nil {
+		return err
+	}
+	fileList := make([]int, 0, tree.Size)
+	volumes := make([]int, len(values))
+	k := 0
+	tree.InOrder(func(key int64, value int) {
+		for values[k] < key {
+			k++
+		}
+		fileList = append(fileList, value)
+		volumes[k]++
+	})
+	sp.ClusterFlist[p] = fileList
+	sp.ClusterVlist[p] = volumes
+	return nil
+}
+
+// refreshPartition 按当前分区内容重新加密分区 p 中从第 from 个关键词开始的前缀位图，前向安全模式下写入新版本的地址，
+// 可验证模式下关键词的写入次数加一
+func (sp *Client) refreshPartition(p, from int, req *UpdateRequest) error {
+	prefix := 0
+	for k, volume := range sp.ClusterVlist[p] {
+		prefix += volume
+		if k < from {
+			continue
+		}
+		if sp.ForwardPrivate {
+			sp.Versions[sp.ClusterKlist[p][k]]++
+		}
+		if sp.Verifiable {
+			sp.Counters[sp.ClusterKlist[p][k]]++
+		}
+		if err := sp.encryptAndStore(sp.ClusterKlist[p][k], sp.ClusterFlist[p][:prefix], req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete 在客户端生成删除消息，并交由本地服务器执行
+func (sp *OurScheme) Delete(w string, docID []*big.Int) error {
+	req, err := sp.Client.Delete(w, docID)
+	if err != nil {
+		return err
+	}
+	return sp.Server.ApplyUpdate(req)
+}
+
+// UpdateOp 按操作类型在客户端生成更新消息，并交由本地服务器执行
+func (sp *OurScheme) UpdateOp(op Op, w string, docID []*big.Int) error {
+	req, err := sp.Client.UpdateOp(op, w, docID)
+	if err != nil {
+		return err
+	}
+	return sp.Server.ApplyUpdate(req)
+}
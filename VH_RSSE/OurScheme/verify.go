@@ -0,0 +1,60 @@
+package OurScheme
+
+import (
+	"crypto/hmac"
+	"fmt"
+)
+
+// VerificationError 可验证模式下服务器返回的结果未通过验证：条目缺失、缺少 MAC，或 MAC 与 token、写入次数、密文不匹配
+type VerificationError struct {
+	Token  string // 未通过验证的 token，结果数量不一致时为空
+	Reason string
+}
+
+func (e *VerificationError) Error() string {
+	if e.Token == "" {
+		return fmt.Sprintf("搜索结果验证失败: %s", e.Reason)
+	}
+	return fmt.Sprintf("搜索结果验证失败: token %s: %s", e.Token, e.Reason)
+}
+
+// verifyAttributes 验证合取查询的属性结果：写入过的属性条目必须返回且 MAC 与写入次数一致，
+// 从未写入的属性条目必须为空，服务器因此无法隐藏、替换或重放属性位图
+func (sp *Client) verifyAttributes(query *ConjunctiveQuery, resp *SearchResponse) error {
+	if len(resp.Results) != len(query.AttrTokens) {
+		return &VerificationError{Reason: fmt.Sprintf("服务器返回 %d 个属性结果，期望 %d 个", len(resp.Results), len(query.AttrTokens))}
+	}
+	for k, token := range query.AttrTokens {
+		counter := query.AttrCounters[k]
+		if counter == 0 {
+			if resp.Results[k] != nil {
+				return &VerificationError{Token: token, Reason: "返回了未写入的属性条目"}
+			}
+			continue
+		}
+		if resp.Results[k] == nil {
+			return &VerificationError{Token: token, Reason: "属性条目缺失"}
+		}
+		if k >= len(resp.Proofs) || !hmac.Equal(resp.Proofs[k], sp.entryMAC(token, counter, resp.Results[k])) {
+			return &VerificationError{Token: token, Reason: "MAC 不匹配"}
+		}
+	}
+	return nil
+}
+
+// verifyResponse 按查询上下文中记录的写入次数逐个验证服务器返回的条目
+func (sp *Client) verifyResponse(query *QueryContext, resp *SearchResponse) error {
+	if len(resp.Results) != len(query.Tokens) {
+		return &VerificationError{Reason: fmt.Sprintf("服务器返回 %d 个结果，期望 %d 个", len(resp.Results), len(query.Tokens))}
+	}
+	if len(resp.Proofs) != len(resp.Results) {
+		return &VerificationError{Reason: fmt.Sprintf("服务器返回 %d 个证明，期望 %d 个", len(resp.Proofs), len(resp.Results))}
+	}
+	for k, token := range query.Tokens {
+		expected := sp.entryMAC(token, query.Counters[token], resp.Results[k])
+		if !hmac.Equal(resp.Proofs[k], expected) {
+			return &VerificationError{Token: token, Reason: "MAC 不匹配"}
+		}
+	}
+	return nil
+}
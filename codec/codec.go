@@ -0,0 +1,144 @@
+// Package codec 将关键词字符串保序映射到本地树使用的 int64 域
+package codec
+
+import (
+	"EfficientAndLowStroageSSE/config"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// Kind 关键词的编码方式
+type Kind int
+
+const (
+	// KindInt 有符号十进制整数，如 "-12"
+	KindInt Kind = iota
+	// KindFixedPoint 定点小数，按 Scale 放大后取整，如纬度 "37.7749"
+	KindFixedPoint
+	// KindFloat64 任意 float64，按 IEEE 754 位模式保序映射
+	KindFloat64
+)
+
+// Codec 关键词编解码器，零值为有符号整数编码
+type Codec struct {
+	Kind  Kind
+	Scale float64 // KindFixedPoint 的放大倍数，如 10000 表示保留四位小数
+}
+
+// Int 有符号整数编码
+func Int() Codec {
+	return Codec{Kind: KindInt}
+}
+
+// FixedPoint 放大倍数为 scale 的定点小数编码
+func FixedPoint(scale float64) Codec {
+	return Codec{Kind: KindFixedPoint, Scale: scale}
+}
+
+// DefaultFixedPoint 使用 config.Divide 作为放大倍数的定点小数编码，与 tool 中经纬度的取整精度一致
+func DefaultFixedPoint() Codec {
+	return FixedPoint(config.Divide)
+}
+
+// Float64 float64 编码
+func Float64() Codec {
+	return Codec{Kind: KindFloat64}
+}
+
+// Encode 将关键词映射为 int64，保持数值顺序：a < b 当且仅当 Encode(a) < Encode(b)
+func (c Codec) Encode(keyword string) (int64, error) {
+	switch c.Kind {
+	case KindInt:
+		v, err := strconv.ParseInt(keyword, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("关键词 %q 不是整数: %v", keyword, err)
+		}
+		return v, nil
+	case KindFixedPoint:
+		if c.Scale <= 0 {
+			return 0, fmt.Errorf("定点小数的放大倍数无效: %v", c.Scale)
+		}
+		v, err := parseFinite(keyword)
+		if err != nil {
+			return 0, err
+		}
+		scaled := v * c.Scale
+		rounded := math.Round(scaled)
+		if math.Abs(rounded) >= math.MaxInt64 {
+			return 0, fmt.Errorf("关键词 %q 放大 %v 倍后超出 int64 范围", keyword, c.Scale)
+		}
+		// 超出精度的关键词取整后可能与相邻关键词相同，破坏保序性
+		if math.Abs(scaled-rounded) > 1e-6*math.Max(1, math.Abs(scaled)) {
+			return 0, fmt.Errorf("关键词 %q 的精度超过 1/%v", keyword, c.Scale)
+		}
+		return int64(rounded), nil
+	case KindFloat64:
+		v, err := parseFinite(keyword)
+		if err != nil {
+			return 0, err
+		}
+		if v == 0 {
+			v = 0 // 将 -0 与 +0 视为同一个值
+		}
+		bits := math.Float64bits(v)
+		// 正数翻转符号位，负数翻转全部位，得到按无符号整数保序的位模式，再平移到 int64
+		if bits>>63 == 1 {
+			bits = ^bits
+		} else {
+			bits |= 1 << 63
+		}
+		return int64(bits ^ 1<<63), nil
+	default:
+		return 0, fmt.Errorf("未知的关键词编码方式: %d", c.Kind)
+	}
+}
+
+// EncodeAll 依次编码 keywords，遇到无法解析的关键词时返回错误
+func (c Codec) EncodeAll(keywords []string) ([]int64, error) {
+	values := make([]int64, len(keywords))
+	for i, keyword := range keywords {
+		v, err := c.Encode(keyword)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// Sort 按编码后的数值对 keywords 原地升序排序
+func (c Codec) Sort(keywords []string) error {
+	values, err := c.EncodeAll(keywords)
+	if err != nil {
+		return err
+	}
+	sort.Sort(byValue{keywords: keywords, values: values})
+	return nil
+}
+
+// byValue 按编码值排序关键词
+type byValue struct {
+	keywords []string
+	values   []int64
+}
+
+func (b byValue) Len() int           { return len(b.keywords) }
+func (b byValue) Less(i, j int) bool { return b.values[i] < b.values[j] }
+func (b byValue) Swap(i, j int) {
+	b.keywords[i], b.keywords[j] = b.keywords[j], b.keywords[i]
+	b.values[i], b.values[j] = b.values[j], b.values[i]
+}
+
+// parseFinite 解析有限的浮点数
+func parseFinite(keyword string) (float64, error) {
+	v, err := strconv.ParseFloat(keyword, 64)
+	if err != nil {
+		return 0, fmt.Errorf("关键词 %q 不是数值: %v", keyword, err)
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("关键词 %q 不是有限数值", keyword)
+	}
+	return v, nil
+}
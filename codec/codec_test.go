@@ -0,0 +1,75 @@
+package codec
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestEncode_orderPreserving 三种编码都保持数值顺序
+func TestEncode_orderPreserving(t *testing.T) {
+	cases := []struct {
+		codec    Codec
+		keywords []string // 按数值升序
+	}{
+		{Int(), []string{"-9223372036854775808", "-12", "-1", "0", "7", "335348"}},
+		{FixedPoint(10000), []string{"-122.4194", "-0.0001", "0", "0.0001", "37.7749", "37.775"}},
+		{Float64(), []string{"-1e300", "-2.5", "-0.0000001", "0", "1e-300", "0.5", "37.7749", "1e300"}},
+	}
+	for _, c := range cases {
+		values, err := c.codec.EncodeAll(c.keywords)
+		if err != nil {
+			t.Fatalf("%+v EncodeAll returned an error: %v", c.codec, err)
+		}
+		for i := 1; i < len(values); i++ {
+			if values[i-1] >= values[i] {
+				t.Errorf("%+v: Encode(%q)=%d is not less than Encode(%q)=%d", c.codec, c.keywords[i-1], values[i-1], c.keywords[i], values[i])
+			}
+		}
+	}
+
+	if v, _ := FixedPoint(10000).Encode("37.7749"); v != 377749 {
+		t.Errorf("FixedPoint Encode mismatch: expected %d, got %d", 377749, v)
+	}
+	neg, _ := Float64().Encode("-0")
+	pos, _ := Float64().Encode("0")
+	if neg != pos {
+		t.Errorf("Float64 Encode(-0)=%d differs from Encode(0)=%d", neg, pos)
+	}
+}
+
+// TestEncode_errors 无法解析或超出精度的关键词返回错误
+func TestEncode_errors(t *testing.T) {
+	cases := []struct {
+		codec   Codec
+		keyword string
+	}{
+		{Int(), "37.7749"},
+		{Int(), "abc"},
+		{FixedPoint(10000), "37.77495"},
+		{FixedPoint(10000), "1e300"},
+		{FixedPoint(0), "1"},
+		{Float64(), "NaN"},
+		{Float64(), "+Inf"},
+		{Codec{Kind: Kind(99)}, "1"},
+	}
+	for _, c := range cases {
+		if v, err := c.codec.Encode(c.keyword); err == nil {
+			t.Errorf("%+v Encode(%q) should fail, got %d", c.codec, c.keyword, v)
+		}
+	}
+}
+
+// TestSort 按编码值排序，而非字符串顺序
+func TestSort(t *testing.T) {
+	keywords := []string{"10.5", "-3.25", "2", "-10"}
+	if err := FixedPoint(100).Sort(keywords); err != nil {
+		t.Fatalf("Sort returned an error: %v", err)
+	}
+	expected := []string{"-10", "-3.25", "2", "10.5"}
+	if !reflect.DeepEqual(keywords, expected) {
+		t.Errorf("Sort mismatch: expected %v, got %v", expected, keywords)
+	}
+	if err := Int().Sort([]string{"1", "x"}); err == nil {
+		t.Errorf("Sort with an invalid keyword should fail")
+	}
+}
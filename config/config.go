@@ -10,6 +10,8 @@ var (
 	Lambda         int     // 安全参数 lambda
 	Divide         float64 // 安全参数 lambda
 	Range          []int
+	Padding        string // OurScheme 结果规模隐藏模式：none、pow2 或 bucket
+	BucketSize     int    // Padding 为 bucket 时的档位大小
 )
 
 // init 函数初始化全局变量
@@ -27,4 +29,8 @@ func init() {
 
 	Divide = 10000
 	Range = []int{0, 335348}
+
+	// 默认不填充结果
+	Padding = "none"
+	BucketSize = 64
 }
@@ -0,0 +1,89 @@
+package rsse
+
+import (
+	"EfficientAndLowStroageSSE/FB_RSSE"
+	"fmt"
+	"math/big"
+)
+
+// fbToken FB_RSSE 的查询令牌
+type fbToken struct {
+	K_w_set [][]byte
+	ST_set  [][]byte
+	c_set   []int
+}
+
+func (t *fbToken) Len() int {
+	return len(t.K_w_set)
+}
+
+// fbScheme 将 FB_RSSE 适配为 Scheme
+type fbScheme struct {
+	sp             *FB_RSSE.SystemParameters
+	sortedKeywords []string // FB_RSSE 生成令牌时需要有序关键词列表
+}
+
+// NewFBRSSE 创建位图长度为 bsLength 的 FB_RSSE
+func NewFBRSSE(bsLength int) Scheme {
+	return &fbScheme{sp: FB_RSSE.Setup(bsLength)}
+}
+
+func (s *fbScheme) Name() string {
+	return "FB_RSSE"
+}
+
+func (s *fbScheme) BuildIndex(invertedIndex map[string][]int, sortedKeywords []string) error {
+	s.sortedKeywords = sortedKeywords
+	return s.sp.BuildIndex(invertedIndex, sortedKeywords)
+}
+
+func (s *fbScheme) GenToken(queryRange [2]string) (Token, error) {
+	K_w_set, ST_set, c_set, err := s.sp.GenToken(queryRange, s.sortedKeywords)
+	if err != nil {
+		return nil, err
+	}
+	return &fbToken{K_w_set: K_w_set, ST_set: ST_set, c_set: c_set}, nil
+}
+
+func (s *fbScheme) ServerSearch(token Token) (Result, error) {
+	t, ok := token.(*fbToken)
+	if !ok {
+		return nil, fmt.Errorf("FB_RSSE 无法处理令牌类型 %T", token)
+	}
+	return s.sp.ServerSearch(t.K_w_set, t.ST_set, t.c_set)
+}
+
+func (s *fbScheme) Resolve(token Token, result Result) ([]int, error) {
+	t, ok := token.(*fbToken)
+	if !ok {
+		return nil, fmt.Errorf("FB_RSSE 无法处理令牌类型 %T", token)
+	}
+	sum, ok := result.(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("FB_RSSE 无法处理结果类型 %T", result)
+	}
+	bs, err := s.sp.LocalParse(t.K_w_set, t.c_set, sum)
+	if err != nil {
+		return nil, err
+	}
+	return bitmapToIDs(bs), nil
+}
+
+func (s *fbScheme) Update(keyword string, docIDs []int) error {
+	bs := big.NewInt(0)
+	for _, id := range docIDs {
+		bs.SetBit(bs, id, 1)
+	}
+	return s.sp.UpdateBigInt(keyword, bs)
+}
+
+// bitmapToIDs 将位图中为 1 的位转换为文件 ID
+func bitmapToIDs(bs *big.Int) []int {
+	ids := []int{}
+	for i := 0; i < bs.BitLen(); i++ {
+		if bs.Bit(i) == 1 {
+			ids = append(ids, i)
+		}
+	}
+	return ids
+}
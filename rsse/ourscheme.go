@@ -0,0 +1,112 @@
+package rsse
+
+import (
+	"EfficientAndLowStroageSSE/VH_RSSE/OurScheme"
+	"fmt"
+	"math/big"
+)
+
+// oursToken OurScheme 的查询令牌
+type oursToken struct {
+	query *OurScheme.QueryContext
+}
+
+func (t *oursToken) Len() int {
+	return len(t.query.Tokens)
+}
+
+// oursScheme 将 OurScheme 适配为 Scheme
+type oursScheme struct {
+	client *OurScheme.Client
+	server *OurScheme.Server
+}
+
+// NewOurScheme 创建分区大小为 L 的 OurScheme
+func NewOurScheme(L int) Scheme {
+	return &oursScheme{
+		client: OurScheme.NewClient(L),
+		server: OurScheme.NewServer(L),
+	}
+}
+
+// NewOurSchemePadded 创建分区大小为 L 的 OurScheme，ResolvePadded 按 padding 模式填充结果，
+// bucketSize 仅在 PadBucket 模式下使用
+func NewOurSchemePadded(L int, padding OurScheme.Padding, bucketSize int) Scheme {
+	s := &oursScheme{
+		client: OurScheme.NewClient(L),
+		server: OurScheme.NewServer(L),
+	}
+	s.client.Padding, s.client.BucketSize = padding, bucketSize
+	return s
+}
+
+func (s *oursScheme) Name() string {
+	return "OurScheme"
+}
+
+func (s *oursScheme) BuildIndex(invertedIndex map[string][]int, sortedKeywords []string) error {
+	req, err := s.client.BuildIndex(invertedIndex, sortedKeywords)
+	if err != nil {
+		return err
+	}
+	return s.server.ApplyUpdate(req)
+}
+
+func (s *oursScheme) GenToken(queryRange [2]string) (Token, error) {
+	query, err := s.client.GenToken(queryRange)
+	if err != nil {
+		return nil, err
+	}
+	return &oursToken{query: query}, nil
+}
+
+func (s *oursScheme) ServerSearch(token Token) (Result, error) {
+	t, ok := token.(*oursToken)
+	if !ok {
+		return nil, fmt.Errorf("OurScheme 无法处理令牌类型 %T", token)
+	}
+	return s.server.Search(t.query.Request()), nil
+}
+
+func (s *oursScheme) Resolve(token Token, result Result) ([]int, error) {
+	t, ok := token.(*oursToken)
+	if !ok {
+		return nil, fmt.Errorf("OurScheme 无法处理令牌类型 %T", token)
+	}
+	resp, ok := result.(*OurScheme.SearchResponse)
+	if !ok {
+		return nil, fmt.Errorf("OurScheme 无法处理结果类型 %T", result)
+	}
+	return s.client.LocalSearch(t.query, resp)
+}
+
+func (s *oursScheme) ResolvePadded(token Token, result Result) ([]int, []int, error) {
+	t, ok := token.(*oursToken)
+	if !ok {
+		return nil, nil, fmt.Errorf("OurScheme 无法处理令牌类型 %T", token)
+	}
+	resp, ok := result.(*OurScheme.SearchResponse)
+	if !ok {
+		return nil, nil, fmt.Errorf("OurScheme 无法处理结果类型 %T", result)
+	}
+	padded, dummies, err := s.client.LocalSearchPadded(t.query, resp)
+	if err != nil {
+		return nil, nil, err
+	}
+	if s.client.Padding == OurScheme.PadNone {
+		return padded, padded, nil
+	}
+	return dummies.Filter(padded), padded, nil
+}
+
+func (s *oursScheme) Update(keyword string, docIDs []int) error {
+	ids := make([]*big.Int, len(docIDs))
+	for i, id := range docIDs {
+		ids[i] = big.NewInt(int64(id))
+	}
+	req, err := s.client.Update(keyword, ids)
+	if err != nil {
+		return err
+	}
+	return s.server.ApplyUpdate(req)
+}
@@ -0,0 +1,31 @@
+package rsse
+
+// Token 查询令牌，由具体方案决定其内容，调用方只需把它原样交给服务器和 Resolve
+type Token interface {
+	Len() int // 需要服务器处理的令牌数量，为 0 时表示无需访问服务器
+}
+
+// Result 服务器返回的加密结果，由具体方案决定其内容
+type Result interface{}
+
+// Scheme 范围可搜索加密方案的统一接口
+type Scheme interface {
+	// Name 方案名称，用于实验输出
+	Name() string
+	// BuildIndex 根据倒排索引构建加密索引
+	BuildIndex(invertedIndex map[string][]int, sortedKeywords []string) error
+	// GenToken 客户端为查询范围生成令牌
+	GenToken(queryRange [2]string) (Token, error)
+	// ServerSearch 服务器根据令牌返回加密结果
+	ServerSearch(token Token) (Result, error)
+	// Resolve 客户端解析服务器结果，得到文件 ID
+	Resolve(token Token, result Result) ([]int, error)
+	// Update 向关键词追加文件 ID
+	Update(keyword string, docIDs []int) error
+}
+
+// Padder 支持结果规模隐藏的方案，ResolvePadded 在 Resolve 的基础上同时返回填充后的文件 ID 列表，
+// 第一个返回值为去掉虚拟文件 ID 后的真实结果
+type Padder interface {
+	ResolvePadded(token Token, result Result) (ids []int, padded []int, err error)
+}
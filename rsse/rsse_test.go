@@ -0,0 +1,111 @@
+package rsse
+
+import (
+	"EfficientAndLowStroageSSE/VH_RSSE/OurScheme"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+// TestSchemes_sameResult 两种方案通过统一接口查询同一范围时返回相同的文件 ID
+func TestSchemes_sameResult(t *testing.T) {
+	invertedIndex := map[string][]int{
+		"1": {1, 3},
+		"2": {4, 2, 5},
+		"3": {6, 7},
+		"4": {8, 9, 10, 11},
+		"5": {12, 13, 14, 15, 16},
+	}
+	sortedKeywords := []string{"1", "2", "3", "4", "5"}
+	queryRanges := [][2]string{{"1", "5"}, {"2", "4"}, {"3", "3"}, {"2", "5"}}
+
+	for _, scheme := range []Scheme{NewOurScheme(10), NewFBRSSE(64)} {
+		if err := scheme.BuildIndex(invertedIndex, sortedKeywords); err != nil {
+			t.Fatalf("%s BuildIndex returned an error: %v", scheme.Name(), err)
+		}
+		// 同一个索引上重复执行全部查询，搜索不应消耗 EDB 中的条目
+		for round := 0; round < 2; round++ {
+			for _, queryRange := range queryRanges {
+				expected := expectedResult(invertedIndex, queryRange)
+				actual, err := search(scheme, queryRange)
+				if err != nil {
+					t.Fatalf("%s search returned an error: %v", scheme.Name(), err)
+				}
+				if !reflect.DeepEqual(actual, expected) {
+					t.Errorf("%s result mismatch for %v in round %d: expected %v, got %v", scheme.Name(), queryRange, round, expected, actual)
+				}
+			}
+		}
+	}
+}
+
+// TestOurScheme_padded 填充模式下 ResolvePadded 返回 2 的幂个文件 ID，去掉虚拟文件 ID 后与明文结果一致
+func TestOurScheme_padded(t *testing.T) {
+	invertedIndex := map[string][]int{
+		"1": {1, 3},
+		"2": {4, 2, 5},
+		"3": {6, 7},
+		"4": {8, 9, 10, 11},
+		"5": {12, 13, 14, 15, 16},
+	}
+	sortedKeywords := []string{"1", "2", "3", "4", "5"}
+	scheme := NewOurSchemePadded(10, OurScheme.PadPowerOfTwo, 0)
+	if err := scheme.BuildIndex(invertedIndex, sortedKeywords); err != nil {
+		t.Fatalf("BuildIndex returned an error: %v", err)
+	}
+	for _, queryRange := range [][2]string{{"1", "5"}, {"2", "4"}, {"3", "3"}} {
+		token, err := scheme.GenToken(queryRange)
+		if err != nil {
+			t.Fatalf("GenToken returned an error: %v", err)
+		}
+		result, err := scheme.ServerSearch(token)
+		if err != nil {
+			t.Fatalf("ServerSearch returned an error: %v", err)
+		}
+		ids, padded, err := scheme.(Padder).ResolvePadded(token, result)
+		if err != nil {
+			t.Fatalf("ResolvePadded returned an error: %v", err)
+		}
+		if n := len(padded); n == 0 || n&(n-1) != 0 {
+			t.Errorf("padded result size for %v should be a power of two, got %d", queryRange, n)
+		}
+		sort.Ints(ids)
+		if expected := expectedResult(invertedIndex, queryRange); !reflect.DeepEqual(ids, expected) {
+			t.Errorf("result mismatch for %v: expected %v, got %v", queryRange, expected, ids)
+		}
+	}
+}
+
+// search 通过统一接口完成一次完整的查询
+func search(scheme Scheme, queryRange [2]string) ([]int, error) {
+	token, err := scheme.GenToken(queryRange)
+	if err != nil {
+		return nil, err
+	}
+	result, err := scheme.ServerSearch(token)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := scheme.Resolve(token, result)
+	if err != nil {
+		return nil, err
+	}
+	sort.Ints(ids)
+	return ids, nil
+}
+
+// expectedResult 明文计算查询范围内的文件 ID
+func expectedResult(invertedIndex map[string][]int, queryRange [2]string) []int {
+	left, _ := strconv.Atoi(queryRange[0])
+	right, _ := strconv.Atoi(queryRange[1])
+	result := []int{}
+	for keyword, ids := range invertedIndex {
+		k, _ := strconv.Atoi(keyword)
+		if k >= left && k <= right {
+			result = append(result, ids...)
+		}
+	}
+	sort.Ints(result)
+	return result
+}
@@ -0,0 +1,185 @@
+// Package spatial 将 (纬度, 经度) 网格化后按 Z-order（Morton 码）线性化为一维关键词，
+// 并把矩形查询分解为若干个一维关键词区间，供一维范围搜索方案使用
+package spatial
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Grid 经纬度网格，每个维度划分为 2^Bits 个单元
+type Grid struct {
+	Bits   int // 每个维度的位数，Morton 码共 2*Bits 位
+	LatMin float64
+	LatMax float64
+	LonMin float64
+	LonMax float64
+}
+
+// DefaultGrid 覆盖全球、每个维度 16 位的网格（单元约 0.003° × 0.005°）
+func DefaultGrid() Grid {
+	return Grid{Bits: 16, LatMin: -90, LatMax: 90, LonMin: -180, LonMax: 180}
+}
+
+// Box 经纬度矩形查询，边界均为闭区间
+type Box struct {
+	LatMin float64
+	LatMax float64
+	LonMin float64
+	LonMax float64
+}
+
+// Point 经纬度坐标
+type Point struct {
+	Lat float64
+	Lon float64
+}
+
+// Contains 判断坐标是否落在矩形内，边界为闭区间
+func (b Box) Contains(p Point) bool {
+	return p.Lat >= b.LatMin && p.Lat <= b.LatMax && p.Lon >= b.LonMin && p.Lon <= b.LonMax
+}
+
+// Range Morton 码闭区间 [Lo, Hi]
+type Range struct {
+	Lo uint64
+	Hi uint64
+}
+
+// validate 检查网格参数
+func (g Grid) validate() error {
+	if g.Bits < 1 || g.Bits > 31 {
+		return fmt.Errorf("网格位数 %d 超出范围 [1, 31]", g.Bits)
+	}
+	if !(g.LatMin < g.LatMax) || !(g.LonMin < g.LonMax) {
+		return fmt.Errorf("网格范围无效: 纬度 [%v, %v]，经度 [%v, %v]", g.LatMin, g.LatMax, g.LonMin, g.LonMax)
+	}
+	return nil
+}
+
+// cell 将 [min, max] 内的坐标映射为单元下标
+func (g Grid) cell(v, min, max float64) (uint32, error) {
+	if math.IsNaN(v) || v < min || v > max {
+		return 0, fmt.Errorf("坐标 %v 超出网格范围 [%v, %v]", v, min, max)
+	}
+	n := uint64(1) << g.Bits
+	c := uint64((v - min) / (max - min) * float64(n))
+	if c >= n { // v == max
+		c = n - 1
+	}
+	return uint32(c), nil
+}
+
+// Cell 返回坐标所在单元的 (x, y)，x 对应经度，y 对应纬度
+func (g Grid) Cell(lat, lon float64) (x, y uint32, err error) {
+	if err := g.validate(); err != nil {
+		return 0, 0, err
+	}
+	if y, err = g.cell(lat, g.LatMin, g.LatMax); err != nil {
+		return 0, 0, err
+	}
+	if x, err = g.cell(lon, g.LonMin, g.LonMax); err != nil {
+		return 0, 0, err
+	}
+	return x, y, nil
+}
+
+// Encode 返回坐标所在单元的 Morton 码
+func (g Grid) Encode(lat, lon float64) (uint64, error) {
+	x, y, err := g.Cell(lat, lon)
+	if err != nil {
+		return 0, err
+	}
+	return Interleave(x, y), nil
+}
+
+// Keyword 返回坐标对应的一维关键词（Morton 码的十进制字符串）
+func (g Grid) Keyword(lat, lon float64) (string, error) {
+	z, err := g.Encode(lat, lon)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatUint(z, 10), nil
+}
+
+// Decompose 将矩形查询分解为按升序排列、互不相邻的 Morton 码区间，
+// 区间的并集恰好是与矩形相交的全部单元
+func (g Grid) Decompose(box Box) ([]Range, error) {
+	if err := g.validate(); err != nil {
+		return nil, err
+	}
+	if !(box.LatMin <= box.LatMax) || !(box.LonMin <= box.LonMax) {
+		return nil, fmt.Errorf("查询矩形无效: %+v", box)
+	}
+	// 将矩形裁剪到网格范围内
+	box.LatMin, box.LatMax = math.Max(box.LatMin, g.LatMin), math.Min(box.LatMax, g.LatMax)
+	box.LonMin, box.LonMax = math.Max(box.LonMin, g.LonMin), math.Min(box.LonMax, g.LonMax)
+	if box.LatMin > box.LatMax || box.LonMin > box.LonMax {
+		return []Range{}, nil
+	}
+	x1, _ := g.cell(box.LonMin, g.LonMin, g.LonMax)
+	x2, _ := g.cell(box.LonMax, g.LonMin, g.LonMax)
+	y1, _ := g.cell(box.LatMin, g.LatMin, g.LatMax)
+	y2, _ := g.cell(box.LatMax, g.LatMin, g.LatMax)
+
+	ranges := []Range{}
+	decompose(0, 0, uint64(1)<<g.Bits, uint64(x1), uint64(x2), uint64(y1), uint64(y2), &ranges)
+	return ranges, nil
+}
+
+// decompose 递归遍历四叉树：节点覆盖 [x0, x0+size) × [y0, y0+size)，
+// 完全落在查询单元范围内的节点整体输出为一个区间，与上一个区间相邻时合并
+func decompose(x0, y0, size, x1, x2, y1, y2 uint64, ranges *[]Range) {
+	if x0 > x2 || x0+size-1 < x1 || y0 > y2 || y0+size-1 < y1 {
+		return
+	}
+	if x0 >= x1 && x0+size-1 <= x2 && y0 >= y1 && y0+size-1 <= y2 {
+		lo := Interleave(uint32(x0), uint32(y0))
+		hi := lo + size*size - 1
+		if n := len(*ranges); n > 0 && (*ranges)[n-1].Hi+1 == lo {
+			(*ranges)[n-1].Hi = hi
+		} else {
+			*ranges = append(*ranges, Range{Lo: lo, Hi: hi})
+		}
+		return
+	}
+	half := size / 2
+	// Z-order 中 x 占低位，子节点顺序为 (0,0)、(1,0)、(0,1)、(1,1)
+	decompose(x0, y0, half, x1, x2, y1, y2, ranges)
+	decompose(x0+half, y0, half, x1, x2, y1, y2, ranges)
+	decompose(x0, y0+half, half, x1, x2, y1, y2, ranges)
+	decompose(x0+half, y0+half, half, x1, x2, y1, y2, ranges)
+}
+
+// Interleave 交错 x 与 y 的各位得到 Morton 码，x 占偶数位，y 占奇数位
+func Interleave(x, y uint32) uint64 {
+	return spread(x) | spread(y)<<1
+}
+
+// Deinterleave Interleave 的逆运算
+func Deinterleave(z uint64) (x, y uint32) {
+	return compact(z), compact(z >> 1)
+}
+
+// spread 将 32 位整数的各位分散到 64 位整数的偶数位
+func spread(v uint32) uint64 {
+	z := uint64(v)
+	z = (z | z<<16) & 0x0000ffff0000ffff
+	z = (z | z<<8) & 0x00ff00ff00ff00ff
+	z = (z | z<<4) & 0x0f0f0f0f0f0f0f0f
+	z = (z | z<<2) & 0x3333333333333333
+	z = (z | z<<1) & 0x5555555555555555
+	return z
+}
+
+// compact spread 的逆运算
+func compact(z uint64) uint32 {
+	z &= 0x5555555555555555
+	z = (z | z>>1) & 0x3333333333333333
+	z = (z | z>>2) & 0x0f0f0f0f0f0f0f0f
+	z = (z | z>>4) & 0x00ff00ff00ff00ff
+	z = (z | z>>8) & 0x0000ffff0000ffff
+	z = (z | z>>16) & 0x00000000ffffffff
+	return uint32(z)
+}
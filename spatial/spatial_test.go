@@ -0,0 +1,76 @@
+package spatial
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// TestInterleave Morton 码可逆，且保持单个维度内的顺序
+func TestInterleave(t *testing.T) {
+	for _, c := range [][2]uint32{{0, 0}, {1, 0}, {0, 1}, {3, 5}, {1<<31 - 1, 12345}, {1<<32 - 1, 1<<32 - 1}} {
+		x, y := Deinterleave(Interleave(c[0], c[1]))
+		if x != c[0] || y != c[1] {
+			t.Errorf("Deinterleave(Interleave(%d, %d)) = (%d, %d)", c[0], c[1], x, y)
+		}
+	}
+	if z := Interleave(0b11, 0b01); z != 0b0111 {
+		t.Errorf("Interleave(3, 1) mismatch: expected %b, got %b", 0b0111, z)
+	}
+}
+
+// TestDecompose 分解得到的区间恰好覆盖与矩形相交的单元，且有序、互不相邻
+func TestDecompose(t *testing.T) {
+	g := Grid{Bits: 4, LatMin: -80, LatMax: 80, LonMin: -160, LonMax: 160}
+	rng := rand.New(rand.NewSource(1))
+	for n := 0; n < 500; n++ {
+		box := Box{
+			LatMin: rng.Float64()*200 - 100, LatMax: rng.Float64()*200 - 100,
+			LonMin: rng.Float64()*400 - 200, LonMax: rng.Float64()*400 - 200,
+		}
+		if box.LatMin > box.LatMax {
+			box.LatMin, box.LatMax = box.LatMax, box.LatMin
+		}
+		if box.LonMin > box.LonMax {
+			box.LonMin, box.LonMax = box.LonMax, box.LonMin
+		}
+		ranges, err := g.Decompose(box)
+		if err != nil {
+			t.Fatalf("Decompose returned an error: %v", err)
+		}
+		covered := map[uint64]bool{}
+		for i, r := range ranges {
+			if r.Lo > r.Hi || (i > 0 && ranges[i-1].Hi+1 >= r.Lo) {
+				t.Fatalf("ranges not sorted and disjoint: %v", ranges)
+			}
+			for z := r.Lo; z <= r.Hi; z++ {
+				covered[z] = true
+			}
+		}
+
+		// 逐个单元判断是否与矩形相交
+		latStep, lonStep := 160.0/16, 320.0/16
+		expected := 0
+		for x := uint32(0); x < 16; x++ {
+			for y := uint32(0); y < 16; y++ {
+				lon0, lat0 := g.LonMin+float64(x)*lonStep, g.LatMin+float64(y)*latStep
+				inside := lon0 <= box.LonMax && lon0+lonStep > box.LonMin && lat0 <= box.LatMax && lat0+latStep > box.LatMin
+				if inside {
+					expected++
+				}
+				if inside != covered[Interleave(x, y)] {
+					t.Fatalf("box %+v: cell (%d, %d) inside=%v, covered=%v", box, x, y, inside, covered[Interleave(x, y)])
+				}
+			}
+		}
+		if len(covered) != expected {
+			t.Fatalf("box %+v: covered %d cells, expected %d", box, len(covered), expected)
+		}
+	}
+
+	if _, err := g.Decompose(Box{LatMin: 10, LatMax: 0}); err == nil {
+		t.Errorf("Decompose of an inverted box should fail")
+	}
+	if _, err := g.Encode(91, 0); err == nil {
+		t.Errorf("Encode outside the grid should fail")
+	}
+}
@@ -0,0 +1,280 @@
+package ssegrpc
+
+import (
+	"EfficientAndLowStroageSSE/FB_RSSE"
+	"EfficientAndLowStroageSSE/VH_RSSE/OurScheme"
+	"EfficientAndLowStroageSSE/ssegrpc/ssepb"
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+
+	"google.golang.org/grpc"
+)
+
+// batchSize BuildIndex 流式上传时每条消息携带的条目数
+const batchSize = 1024
+
+// OurSchemeClient 在本地执行 GenToken/LocalSearch，通过 gRPC 访问 OurScheme 的 EDB
+type OurSchemeClient struct {
+	*OurScheme.Client
+	rpc ssepb.SSEClient
+}
+
+// NewOurSchemeClient 创建 OurScheme 的远程客户端
+func NewOurSchemeClient(client *OurScheme.Client, conn grpc.ClientConnInterface) *OurSchemeClient {
+	return &OurSchemeClient{Client: client, rpc: ssepb.NewSSEClient(conn)}
+}
+
+// BuildIndex 在本地构建索引并流式上传全部加密条目
+func (c *OurSchemeClient) BuildIndex(ctx context.Context, invertedIndex map[string][]int, keywords []string) error {
+	req, err := c.Client.BuildIndex(invertedIndex, keywords)
+	if err != nil {
+		return err
+	}
+	return uploadIndex(ctx, c.rpc, ssepb.Scheme_SCHEME_OURSCHEME, oursEntries(req))
+}
+
+// Update 在本地生成更新消息并发送到服务器
+func (c *OurSchemeClient) Update(ctx context.Context, w string, docID []*big.Int) error {
+	req, err := c.Client.Update(w, docID)
+	if err != nil {
+		return err
+	}
+	_, err = c.rpc.Update(ctx, &ssepb.UpdateRequest{Scheme: ssepb.Scheme_SCHEME_OURSCHEME, Entries: oursEntries(req)})
+	return err
+}
+
+// Delete 在本地生成删除消息并发送到服务器
+func (c *OurSchemeClient) Delete(ctx context.Context, w string, docID []*big.Int) error {
+	req, err := c.Client.Delete(w, docID)
+	if err != nil {
+		return err
+	}
+	_, err = c.rpc.Delete(ctx, &ssepb.UpdateRequest{Scheme: ssepb.Scheme_SCHEME_OURSCHEME, Entries: oursEntries(req)})
+	return err
+}
+
+// BatchUpdate 在本地生成批量更新消息，并以一次 RPC 发送到服务器
+func (c *OurSchemeClient) BatchUpdate(ctx context.Context, updates []OurScheme.Update) error {
+	req, err := c.Client.BatchUpdate(updates)
+	if err != nil {
+		return err
+	}
+	_, err = c.rpc.Update(ctx, &ssepb.UpdateRequest{Scheme: ssepb.Scheme_SCHEME_OURSCHEME, Entries: oursEntries(req)})
+	return err
+}
+
+// Search 完成一次远程范围查询，返回查询范围内的文件 ID
+func (c *OurSchemeClient) Search(ctx context.Context, queryRange [2]string) ([]int, error) {
+	query, err := c.GenToken(queryRange)
+	if err != nil {
+		return nil, err
+	}
+	if query.Empty {
+		return []int{}, nil
+	}
+	results, err := search(ctx, c.rpc, &ssepb.SearchRequest{Scheme: ssepb.Scheme_SCHEME_OURSCHEME, Tokens: query.Tokens})
+	if err != nil {
+		return nil, err
+	}
+	resp := &OurScheme.SearchResponse{Results: [][]byte{}}
+	for _, result := range results {
+		resp.Results = append(resp.Results, result.Value)
+		if len(result.Proof) > 0 {
+			resp.Proofs = append(resp.Proofs, result.Proof)
+		}
+	}
+	return c.LocalSearch(query, resp)
+}
+
+// oursEntries 将 OurScheme 的更新消息转换为条目，可验证模式下附带条目的 MAC
+func oursEntries(req *OurScheme.UpdateRequest) []*ssepb.Entry {
+	entries := make([]*ssepb.Entry, 0, len(req.Entries))
+	for token, value := range req.Entries {
+		entries = append(entries, &ssepb.Entry{Key: []byte(token), Value: value, Mac: req.MACs[token]})
+	}
+	return entries
+}
+
+// FBClient 在本地执行 GenToken/LocalParse，通过 gRPC 访问 FB_RSSE 的 EDB
+// 本地 SystemParameters 的 EDB 只作为生成条目的缓冲区，上传后即清空
+type FBClient struct {
+	sp             *FB_RSSE.SystemParameters
+	sortedKeywords []string
+	rpc            ssepb.SSEClient
+}
+
+// NewFBClient 创建 FB_RSSE 的远程客户端
+func NewFBClient(sp *FB_RSSE.SystemParameters, conn grpc.ClientConnInterface) *FBClient {
+	return &FBClient{sp: sp, rpc: ssepb.NewSSEClient(conn)}
+}
+
+// BuildIndex 在本地构建索引并流式上传全部加密条目
+func (c *FBClient) BuildIndex(ctx context.Context, invertedIndex map[string][]int, sortedKeywords []string) error {
+	c.sortedKeywords = sortedKeywords
+	if err := c.sp.BuildIndex(invertedIndex, sortedKeywords); err != nil {
+		return err
+	}
+	return uploadIndex(ctx, c.rpc, ssepb.Scheme_SCHEME_FB_RSSE, c.takeEntries())
+}
+
+// Update 将 docIDs 加入关键词 keyword，并把路径上新生成的条目发送到服务器
+func (c *FBClient) Update(ctx context.Context, keyword string, docIDs []int) error {
+	bs := big.NewInt(0)
+	for _, id := range docIDs {
+		bs.SetBit(bs, id, 1)
+	}
+	if err := c.sp.UpdateBigInt(keyword, bs); err != nil {
+		return err
+	}
+	_, err := c.rpc.Update(ctx, &ssepb.UpdateRequest{Scheme: ssepb.Scheme_SCHEME_FB_RSSE, Entries: c.takeEntries()})
+	return err
+}
+
+// Delete 从关键词 keyword 中删除 docIDs，并把路径上新生成的条目发送到服务器
+func (c *FBClient) Delete(ctx context.Context, keyword string, docIDs []int) error {
+	if err := c.sp.Delete(keyword, docIDs); err != nil {
+		return err
+	}
+	_, err := c.rpc.Delete(ctx, &ssepb.UpdateRequest{Scheme: ssepb.Scheme_SCHEME_FB_RSSE, Entries: c.takeEntries()})
+	return err
+}
+
+// BatchUpdate 批量添加与删除文件，每个受影响的节点只生成一个条目，并以一次 RPC 发送到服务器
+func (c *FBClient) BatchUpdate(ctx context.Context, updates []FB_RSSE.Update) error {
+	if _, err := c.sp.BatchUpdate(updates); err != nil {
+		return err
+	}
+	_, err := c.rpc.Update(ctx, &ssepb.UpdateRequest{Scheme: ssepb.Scheme_SCHEME_FB_RSSE, Entries: c.takeEntries()})
+	return err
+}
+
+// Search 完成一次远程范围查询，返回查询范围内的文件 ID
+func (c *FBClient) Search(ctx context.Context, queryRange [2]string) ([]int, error) {
+	K_w_set, ST_set, c_set, err := c.sp.GenToken(queryRange, c.sortedKeywords)
+	if err != nil {
+		return nil, err
+	}
+	// 可验证模式下请求服务器返回链上的全部条目作为证明
+	bs, _, err := c.search(ctx, K_w_set, ST_set, c_set, c.sp.Verifiable)
+	if err != nil {
+		return nil, err
+	}
+	ids := []int{}
+	for i := 0; i < bs.BitLen(); i++ {
+		if bs.Bit(i) == 1 {
+			ids = append(ids, i)
+		}
+	}
+	return ids, nil
+}
+
+// Consolidate 远程查询范围内的节点并将每个节点的链合并为一个条目，旧条目由服务器在同一次 Update 中删除
+func (c *FBClient) Consolidate(ctx context.Context, queryRange [2]string) error {
+	K_w_set, ST_set, c_set, err := c.sp.GenToken(queryRange, c.sortedKeywords)
+	if err != nil {
+		return err
+	}
+	_, nodes, err := c.search(ctx, K_w_set, ST_set, c_set, true)
+	if err != nil {
+		return err
+	}
+	msg, err := c.sp.Consolidate(queryRange, c.sortedKeywords, K_w_set, c_set, nodes)
+	if err != nil {
+		return err
+	}
+	_, err = c.rpc.Update(ctx, &ssepb.UpdateRequest{Scheme: ssepb.Scheme_SCHEME_FB_RSSE, Entries: c.takeEntries(), Deletes: msg.Deletes})
+	return err
+}
+
+// search 发送 FB_RSSE 查询并解密结果，proof 为 true 时服务器返回链上的全部条目，
+// 返回的节点结果列出这些条目的地址，可验证模式下同时验证结果
+func (c *FBClient) search(ctx context.Context, K_w_set, ST_set [][]byte, c_set []int, proof bool) (*big.Int, []FB_RSSE.NodeResult, error) {
+	req := &ssepb.SearchRequest{Scheme: ssepb.Scheme_SCHEME_FB_RSSE, Proof: proof}
+	for i := range K_w_set {
+		req.Nodes = append(req.Nodes, &ssepb.FBNode{KW: K_w_set[i], St: ST_set[i], C: int64(c_set[i])})
+	}
+	results, err := search(ctx, c.rpc, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(results) != len(K_w_set) {
+		return nil, nil, fmt.Errorf("服务器返回 %d 个结果，期望 %d 个", len(results), len(K_w_set))
+	}
+	Sum := big.NewInt(0)
+	searchProof := &FB_RSSE.SearchProof{Nodes: make([][]FB_RSSE.Data, len(results))}
+	nodes := make([]FB_RSSE.NodeResult, len(results))
+	for i, result := range results {
+		nodes[i].Sum_e = new(big.Int).SetBytes(result.Value)
+		Sum = c.sp.Add(Sum, nodes[i].Sum_e)
+		for _, entry := range result.Chain {
+			searchProof.Nodes[i] = append(searchProof.Nodes[i], fbData(entry))
+			nodes[i].UTs = append(nodes[i].UTs, entry.Key)
+		}
+	}
+	var bs *big.Int
+	if c.sp.Verifiable {
+		bs, err = c.sp.LocalParseVerified(K_w_set, ST_set, c_set, Sum, searchProof)
+	} else {
+		bs, err = c.sp.LocalParse(K_w_set, c_set, Sum)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return bs, nodes, nil
+}
+
+// takeEntries 取出本地缓冲的 EDB 条目并清空缓冲区
+func (c *FBClient) takeEntries() []*ssepb.Entry {
+	entries := make([]*ssepb.Entry, 0, len(c.sp.EDB))
+	for UT, data := range c.sp.EDB {
+		entries = append(entries, &ssepb.Entry{Key: []byte(UT), Value: data.BigIntValue.Bytes(), Chain: data.ByteValue, Mac: data.Tag})
+	}
+	c.sp.EDB = make(map[string]FB_RSSE.Data)
+	return entries
+}
+
+// uploadIndex 按 batchSize 分批流式上传条目
+func uploadIndex(ctx context.Context, rpc ssepb.SSEClient, scheme ssepb.Scheme, entries []*ssepb.Entry) error {
+	stream, err := rpc.BuildIndex(ctx)
+	if err != nil {
+		return err
+	}
+	for start := 0; start < len(entries) || start == 0; start += batchSize {
+		end := min(start+batchSize, len(entries))
+		if err := stream.Send(&ssepb.BuildIndexRequest{Scheme: scheme, Entries: entries[start:end]}); err != nil {
+			return err
+		}
+	}
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+	if resp.Entries != uint64(len(entries)) {
+		return fmt.Errorf("服务器接收 %d 个条目，期望 %d 个", resp.Entries, len(entries))
+	}
+	return nil
+}
+
+// search 发送查询并按 index 顺序收集流式返回的结果
+func search(ctx context.Context, rpc ssepb.SSEClient, req *ssepb.SearchRequest) ([]*ssepb.SearchResult, error) {
+	stream, err := rpc.Search(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	results := []*ssepb.SearchResult{}
+	for {
+		result, err := stream.Recv()
+		if err == io.EOF {
+			return results, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if int(result.Index) != len(results) {
+			return nil, fmt.Errorf("结果顺序错误: 收到第 %d 个，期望第 %d 个", result.Index, len(results))
+		}
+		results = append(results, result)
+	}
+}
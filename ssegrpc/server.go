@@ -0,0 +1,235 @@
+// Package ssegrpc 通过 gRPC 提供 OurScheme 与 FB_RSSE 的服务器端协议（见 ssepb/sse.proto），
+// 并提供对应的远程客户端
+package ssegrpc
+
+import (
+	"EfficientAndLowStroageSSE/FB_RSSE"
+	"EfficientAndLowStroageSSE/VH_RSSE/OurScheme"
+	"EfficientAndLowStroageSSE/ssegrpc/ssepb"
+	"context"
+	"io"
+	"math/big"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// counters 单个方案的请求计数
+type counters struct {
+	searches uint64
+	updates  uint64
+	deletes  uint64
+}
+
+// Server 同时持有两种方案 EDB 的 gRPC 服务器
+type Server struct {
+	ssepb.UnimplementedSSEServer
+
+	mu   sync.RWMutex
+	L    int
+	ours *OurScheme.Server
+	// fb 只使用其中的 EDB 与 ServerSearchNodes，随机生成的密钥不会被使用
+	fb    *FB_RSSE.SystemParameters
+	stats map[ssepb.Scheme]*counters
+}
+
+// NewServer 创建 OurScheme 位图长度为 L、FB_RSSE 位图长度为 bsLength 的服务器
+func NewServer(L, bsLength int) *Server {
+	return &Server{
+		L:    L,
+		ours: OurScheme.NewServer(L),
+		fb:   FB_RSSE.Setup(bsLength),
+		stats: map[ssepb.Scheme]*counters{
+			ssepb.Scheme_SCHEME_OURSCHEME: {},
+			ssepb.Scheme_SCHEME_FB_RSSE:   {},
+		},
+	}
+}
+
+// BuildIndex 接收流式上传的全部条目，上传完成后整体替换对应方案的 EDB
+func (s *Server) BuildIndex(stream ssepb.SSE_BuildIndexServer) error {
+	scheme := ssepb.Scheme_SCHEME_UNSPECIFIED
+	var entries []*ssepb.Entry
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if scheme == ssepb.Scheme_SCHEME_UNSPECIFIED {
+			scheme = req.Scheme
+		}
+		if req.Scheme != scheme {
+			return status.Errorf(codes.InvalidArgument, "同一次上传中方案不一致: %v 与 %v", scheme, req.Scheme)
+		}
+		entries = append(entries, req.Entries...)
+	}
+
+	switch scheme {
+	case ssepb.Scheme_SCHEME_OURSCHEME:
+		server := OurScheme.NewServer(s.L)
+		if err := server.ApplyUpdate(oursUpdate(entries)); err != nil {
+			return status.Errorf(codes.InvalidArgument, "写入 OurScheme 条目失败: %v", err)
+		}
+		s.mu.Lock()
+		s.ours = server
+		s.mu.Unlock()
+	case ssepb.Scheme_SCHEME_FB_RSSE:
+		EDB := make(map[string]FB_RSSE.Data, len(entries))
+		applyFB(EDB, entries)
+		s.mu.Lock()
+		s.fb.EDB = EDB
+		s.mu.Unlock()
+	default:
+		return status.Errorf(codes.InvalidArgument, "未知方案: %v", scheme)
+	}
+	return stream.SendAndClose(&ssepb.BuildIndexResponse{Entries: uint64(len(entries))})
+}
+
+// Update 删除请求中列出的条目后将更新条目写入对应方案的 EDB
+func (s *Server) Update(ctx context.Context, req *ssepb.UpdateRequest) (*ssepb.UpdateResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.apply(req); err != nil {
+		return nil, err
+	}
+	s.stats[req.Scheme].updates++
+	return &ssepb.UpdateResponse{Entries: uint64(len(req.Entries))}, nil
+}
+
+// Delete 将客户端删除文件后生成的条目写入对应方案的 EDB，处理与 Update 相同
+func (s *Server) Delete(ctx context.Context, req *ssepb.UpdateRequest) (*ssepb.UpdateResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.apply(req); err != nil {
+		return nil, err
+	}
+	s.stats[req.Scheme].deletes++
+	return &ssepb.UpdateResponse{Entries: uint64(len(req.Entries))}, nil
+}
+
+// apply 删除请求中列出的条目后将条目写入对应方案的 EDB，调用方须持有写锁
+func (s *Server) apply(req *ssepb.UpdateRequest) error {
+	switch req.Scheme {
+	case ssepb.Scheme_SCHEME_OURSCHEME:
+		if len(req.Deletes) > 0 {
+			return status.Errorf(codes.InvalidArgument, "OurScheme 的更新不支持删除条目")
+		}
+		if err := s.ours.ApplyUpdate(oursUpdate(req.Entries)); err != nil {
+			return status.Errorf(codes.InvalidArgument, "写入 OurScheme 条目失败: %v", err)
+		}
+	case ssepb.Scheme_SCHEME_FB_RSSE:
+		msg := &FB_RSSE.ConsolidateMessage{Deletes: req.Deletes, Entries: make(map[string]FB_RSSE.Data, len(req.Entries))}
+		applyFB(msg.Entries, req.Entries)
+		s.fb.ApplyConsolidate(msg)
+	default:
+		return status.Errorf(codes.InvalidArgument, "未知方案: %v", req.Scheme)
+	}
+	return nil
+}
+
+// Search 根据查询令牌流式返回加密结果，每个 token（或 FB_RSSE 节点）一条消息
+func (s *Server) Search(req *ssepb.SearchRequest, stream ssepb.SSE_SearchServer) error {
+	var results []*ssepb.SearchResult
+	s.mu.Lock()
+	switch req.Scheme {
+	case ssepb.Scheme_SCHEME_OURSCHEME:
+		resp := s.ours.Search(&OurScheme.SearchRequest{Tokens: req.Tokens})
+		for i, value := range resp.Results {
+			result := &ssepb.SearchResult{Index: uint32(i), Value: value}
+			if i < len(resp.Proofs) {
+				result.Proof = resp.Proofs[i]
+			}
+			results = append(results, result)
+		}
+	case ssepb.Scheme_SCHEME_FB_RSSE:
+		K_w_set := make([][]byte, len(req.Nodes))
+		ST_set := make([][]byte, len(req.Nodes))
+		c_set := make([]int, len(req.Nodes))
+		for i, node := range req.Nodes {
+			K_w_set[i], ST_set[i], c_set[i] = node.KW, node.St, int(node.C)
+		}
+		nodes, err := s.fb.ServerSearchNodes(K_w_set, ST_set, c_set)
+		if err != nil {
+			s.mu.Unlock()
+			return status.Errorf(codes.Internal, "搜索失败: %v", err)
+		}
+		for i, node := range nodes {
+			result := &ssepb.SearchResult{Index: uint32(i), Value: node.Sum_e.Bytes()}
+			if req.Proof {
+				for _, UT := range node.UTs {
+					data := s.fb.EDB[string(UT)]
+					result.Chain = append(result.Chain, &ssepb.Entry{Key: UT, Value: data.BigIntValue.Bytes(), Chain: data.ByteValue, Mac: data.Tag})
+				}
+			}
+			results = append(results, result)
+		}
+	default:
+		s.mu.Unlock()
+		return status.Errorf(codes.InvalidArgument, "未知方案: %v", req.Scheme)
+	}
+	s.stats[req.Scheme].searches++
+	s.mu.Unlock()
+
+	for _, result := range results {
+		if err := stream.Send(result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stats 返回对应方案的 EDB 规模与请求计数
+func (s *Server) Stats(ctx context.Context, req *ssepb.StatsRequest) (*ssepb.StatsResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var entries int
+	switch req.Scheme {
+	case ssepb.Scheme_SCHEME_OURSCHEME:
+		entries = len(s.ours.EDB)
+	case ssepb.Scheme_SCHEME_FB_RSSE:
+		entries = len(s.fb.EDB)
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "未知方案: %v", req.Scheme)
+	}
+	return &ssepb.StatsResponse{
+		Entries:  uint64(entries),
+		Searches: s.stats[req.Scheme].searches,
+		Updates:  s.stats[req.Scheme].updates,
+		Deletes:  s.stats[req.Scheme].deletes,
+	}, nil
+}
+
+// oursUpdate 将条目转换为 OurScheme 的更新消息
+func oursUpdate(entries []*ssepb.Entry) *OurScheme.UpdateRequest {
+	req := OurScheme.NewUpdateRequest()
+	for _, entry := range entries {
+		req.Entries[string(entry.Key)] = entry.Value
+		if len(entry.Mac) > 0 {
+			req.MACs[string(entry.Key)] = entry.Mac
+		}
+	}
+	return req
+}
+
+// applyFB 将条目写入 FB_RSSE 的 EDB
+func applyFB(EDB map[string]FB_RSSE.Data, entries []*ssepb.Entry) {
+	for _, entry := range entries {
+		EDB[string(entry.Key)] = fbData(entry)
+	}
+}
+
+// fbData 将条目转换为 FB_RSSE 的 EDB 数据
+func fbData(entry *ssepb.Entry) FB_RSSE.Data {
+	data := FB_RSSE.Data{
+		BigIntValue: new(big.Int).SetBytes(entry.Value),
+		ByteValue:   entry.Chain,
+	}
+	if len(entry.Mac) > 0 {
+		data.Tag = entry.Mac
+	}
+	return data
+}
@@ -0,0 +1,368 @@
+package ssegrpc
+
+import (
+	"EfficientAndLowStroageSSE/FB_RSSE"
+	"EfficientAndLowStroageSSE/VH_RSSE/OurScheme"
+	"EfficientAndLowStroageSSE/ssegrpc/ssepb"
+	"context"
+	"errors"
+	"math/big"
+	"net"
+	"reflect"
+	"sort"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// dialBufconn 在内存监听器上启动服务器并返回连接
+func dialBufconn(t *testing.T, server *Server) *grpc.ClientConn {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	ssepb.RegisterSSEServer(s, server)
+	go s.Serve(listener)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("grpc.NewClient returned an error: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// TestRemoteSearch 两种方案经 gRPC 上传索引、查询、更新，结果与明文一致
+func TestRemoteSearch(t *testing.T) {
+	invertedIndex := map[string][]int{
+		"1": {1, 3},
+		"2": {4, 2, 5},
+		"3": {6, 7},
+		"4": {8, 9, 10, 11},
+		"5": {12, 13, 14, 15, 16},
+	}
+	sortedKeywords := []string{"1", "2", "3", "4", "5"}
+	queries := map[[2]string][]int{
+		{"1", "5"}: {1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+		{"2", "4"}: {2, 4, 5, 6, 7, 8, 9, 10, 11},
+		{"3", "3"}: {6, 7},
+	}
+
+	ctx := context.Background()
+	server := NewServer(10, 64)
+	conn := dialBufconn(t, server)
+	ours := NewOurSchemeClient(OurScheme.NewClient(10), conn)
+	fb := NewFBClient(FB_RSSE.Setup(64), conn)
+
+	if err := ours.BuildIndex(ctx, invertedIndex, sortedKeywords); err != nil {
+		t.Fatalf("OurScheme BuildIndex returned an error: %v", err)
+	}
+	if err := fb.BuildIndex(ctx, invertedIndex, sortedKeywords); err != nil {
+		t.Fatalf("FB_RSSE BuildIndex returned an error: %v", err)
+	}
+	if len(fb.sp.EDB) != 0 {
+		t.Errorf("FB_RSSE client kept %d EDB entries after upload", len(fb.sp.EDB))
+	}
+
+	for queryRange, expected := range queries {
+		for name, search := range map[string]func(context.Context, [2]string) ([]int, error){
+			"OurScheme": ours.Search,
+			"FB_RSSE":   fb.Search,
+		} {
+			result, err := search(ctx, queryRange)
+			if err != nil {
+				t.Fatalf("%s Search %v returned an error: %v", name, queryRange, err)
+			}
+			sort.Ints(result)
+			if !reflect.DeepEqual(result, expected) {
+				t.Errorf("%s Search %v mismatch: expected %v, got %v", name, queryRange, expected, result)
+			}
+		}
+	}
+
+	if err := ours.Update(ctx, "3", []*big.Int{big.NewInt(20)}); err != nil {
+		t.Errorf("OurScheme Update returned an error: %v", err)
+	}
+	if err := fb.Update(ctx, "3", []int{20}); err != nil {
+		t.Fatalf("FB_RSSE Update returned an error: %v", err)
+	}
+	result, err := fb.Search(ctx, [2]string{"3", "3"})
+	if err != nil {
+		t.Fatalf("FB_RSSE Search returned an error: %v", err)
+	}
+	if expected := []int{6, 7, 20}; !reflect.DeepEqual(result, expected) {
+		t.Errorf("FB_RSSE Search after Update mismatch: expected %v, got %v", expected, result)
+	}
+
+	batch := map[string]func() error{
+		"OurScheme": func() error {
+			return ours.BatchUpdate(ctx, []OurScheme.Update{
+				{Op: OurScheme.OpAdd, Keyword: "3", DocIDs: []*big.Int{big.NewInt(21)}},
+				{Op: OurScheme.OpDel, Keyword: "4", DocIDs: []*big.Int{big.NewInt(8)}},
+			})
+		},
+		"FB_RSSE": func() error {
+			return fb.BatchUpdate(ctx, []FB_RSSE.Update{
+				{Keyword: "3", DocIDs: []int{21}},
+				{Keyword: "4", DocIDs: []int{8}, Delete: true},
+			})
+		},
+	}
+	for name, update := range batch {
+		if err := update(); err != nil {
+			t.Fatalf("%s BatchUpdate returned an error: %v", name, err)
+		}
+	}
+	for name, search := range map[string]func(context.Context, [2]string) ([]int, error){
+		"OurScheme": ours.Search,
+		"FB_RSSE":   fb.Search,
+	} {
+		result, err := search(ctx, [2]string{"3", "4"})
+		if err != nil {
+			t.Fatalf("%s Search returned an error: %v", name, err)
+		}
+		sort.Ints(result)
+		if expected := []int{6, 7, 9, 10, 11, 20, 21}; !reflect.DeepEqual(result, expected) {
+			t.Errorf("%s Search after BatchUpdate mismatch: expected %v, got %v", name, expected, result)
+		}
+	}
+
+	if err := ours.Delete(ctx, "3", []*big.Int{big.NewInt(6)}); err != nil {
+		t.Fatalf("OurScheme Delete returned an error: %v", err)
+	}
+	if err := fb.Delete(ctx, "3", []int{6}); err != nil {
+		t.Fatalf("FB_RSSE Delete returned an error: %v", err)
+	}
+	for name, search := range map[string]func(context.Context, [2]string) ([]int, error){
+		"OurScheme": ours.Search,
+		"FB_RSSE":   fb.Search,
+	} {
+		result, err := search(ctx, [2]string{"3", "4"})
+		if err != nil {
+			t.Fatalf("%s Search returned an error: %v", name, err)
+		}
+		sort.Ints(result)
+		if expected := []int{7, 9, 10, 11, 20, 21}; !reflect.DeepEqual(result, expected) {
+			t.Errorf("%s Search after Delete mismatch: expected %v, got %v", name, expected, result)
+		}
+	}
+
+	rpc := ssepb.NewSSEClient(conn)
+	stats, err := rpc.Stats(ctx, &ssepb.StatsRequest{Scheme: ssepb.Scheme_SCHEME_FB_RSSE})
+	if err != nil {
+		t.Fatalf("Stats returned an error: %v", err)
+	}
+	if stats.Searches != uint64(len(queries)+3) || stats.Updates != 2 || stats.Deletes != 1 || stats.Entries == 0 {
+		t.Errorf("FB_RSSE stats mismatch: %v", stats)
+	}
+	stats, err = rpc.Stats(ctx, &ssepb.StatsRequest{Scheme: ssepb.Scheme_SCHEME_OURSCHEME})
+	if err != nil {
+		t.Fatalf("Stats returned an error: %v", err)
+	}
+	if stats.Searches != uint64(len(queries)+2) || stats.Deletes != 1 || stats.Entries != uint64(len(invertedIndex)) {
+		t.Errorf("OurScheme stats mismatch: %v", stats)
+	}
+	if _, err := rpc.Stats(ctx, &ssepb.StatsRequest{}); err == nil {
+		t.Errorf("Stats without a scheme should fail")
+	}
+}
+
+// TestRemoteConsolidate FB_RSSE 经 gRPC 合并节点链，服务器删除旧条目，合并前后查询结果一致
+func TestRemoteConsolidate(t *testing.T) {
+	invertedIndex := map[string][]int{
+		"1": {1, 3},
+		"2": {4, 2, 5},
+		"3": {6, 7},
+		"4": {8, 9, 10, 11},
+		"5": {12, 13, 14, 15, 16},
+	}
+	sortedKeywords := []string{"1", "2", "3", "4", "5"}
+	queryRange := [2]string{"2", "3"}
+
+	for _, verifiable := range []bool{false, true} {
+		ctx := context.Background()
+		server := NewServer(10, 64)
+		conn := dialBufconn(t, server)
+		rpc := ssepb.NewSSEClient(conn)
+		sp := FB_RSSE.Setup(64)
+		sp.Verifiable = verifiable
+		fb := NewFBClient(sp, conn)
+		if err := fb.BuildIndex(ctx, invertedIndex, sortedKeywords); err != nil {
+			t.Fatalf("BuildIndex returned an error: %v", err)
+		}
+		for _, id := range []int{20, 21, 22} {
+			if err := fb.Update(ctx, "3", []int{id}); err != nil {
+				t.Fatalf("Update returned an error: %v", err)
+			}
+		}
+		before, err := rpc.Stats(ctx, &ssepb.StatsRequest{Scheme: ssepb.Scheme_SCHEME_FB_RSSE})
+		if err != nil {
+			t.Fatalf("Stats returned an error: %v", err)
+		}
+		if err := fb.Consolidate(ctx, queryRange); err != nil {
+			t.Fatalf("Verifiable=%v: Consolidate returned an error: %v", verifiable, err)
+		}
+		after, err := rpc.Stats(ctx, &ssepb.StatsRequest{Scheme: ssepb.Scheme_SCHEME_FB_RSSE})
+		if err != nil {
+			t.Fatalf("Stats returned an error: %v", err)
+		}
+		if after.Entries >= before.Entries {
+			t.Errorf("Verifiable=%v: server EDB not reduced by Consolidate: before %d, after %d", verifiable, before.Entries, after.Entries)
+		}
+		if len(fb.sp.EDB) != 0 {
+			t.Errorf("Verifiable=%v: client kept %d EDB entries after Consolidate", verifiable, len(fb.sp.EDB))
+		}
+
+		if err := fb.Update(ctx, "2", []int{30}); err != nil {
+			t.Fatalf("Update returned an error: %v", err)
+		}
+		result, err := fb.Search(ctx, queryRange)
+		if err != nil {
+			t.Fatalf("Verifiable=%v: Search after Consolidate returned an error: %v", verifiable, err)
+		}
+		if expected := []int{2, 4, 5, 6, 7, 20, 21, 22, 30}; !reflect.DeepEqual(result, expected) {
+			t.Errorf("Verifiable=%v: Search after Consolidate mismatch: expected %v, got %v", verifiable, expected, result)
+		}
+
+		// OurScheme 的更新不接受删除
+		if _, err := rpc.Update(ctx, &ssepb.UpdateRequest{Scheme: ssepb.Scheme_SCHEME_OURSCHEME, Deletes: [][]byte{[]byte("token")}}); err == nil {
+			t.Errorf("OurScheme Update with deletes should fail")
+		}
+	}
+}
+
+// TestRemoteMalformed 长度与 L 不符的 OurScheme 条目在 BuildIndex、Update 与 Delete 中被拒绝，EDB 保持不变
+func TestRemoteMalformed(t *testing.T) {
+	invertedIndex := map[string][]int{
+		"1": {1, 3},
+		"2": {4, 2, 5},
+		"3": {6, 7},
+	}
+	sortedKeywords := []string{"1", "2", "3"}
+
+	ctx := context.Background()
+	server := NewServer(10, 64)
+	conn := dialBufconn(t, server)
+	rpc := ssepb.NewSSEClient(conn)
+	ours := NewOurSchemeClient(OurScheme.NewClient(10), conn)
+	if err := ours.BuildIndex(ctx, invertedIndex, sortedKeywords); err != nil {
+		t.Fatalf("BuildIndex returned an error: %v", err)
+	}
+	before, err := rpc.Stats(ctx, &ssepb.StatsRequest{Scheme: ssepb.Scheme_SCHEME_OURSCHEME})
+	if err != nil {
+		t.Fatalf("Stats returned an error: %v", err)
+	}
+
+	entries := []*ssepb.Entry{{Key: []byte("token"), Value: []byte("short")}}
+	stream, err := rpc.BuildIndex(ctx)
+	if err != nil {
+		t.Fatalf("BuildIndex stream returned an error: %v", err)
+	}
+	if err := stream.Send(&ssepb.BuildIndexRequest{Scheme: ssepb.Scheme_SCHEME_OURSCHEME, Entries: entries}); err != nil {
+		t.Fatalf("Send returned an error: %v", err)
+	}
+	if _, err := stream.CloseAndRecv(); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("BuildIndex with a malformed entry: expected InvalidArgument, got %v", err)
+	}
+	req := &ssepb.UpdateRequest{Scheme: ssepb.Scheme_SCHEME_OURSCHEME, Entries: entries}
+	if _, err := rpc.Update(ctx, req); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Update with a malformed entry: expected InvalidArgument, got %v", err)
+	}
+	if _, err := rpc.Delete(ctx, req); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Delete with a malformed entry: expected InvalidArgument, got %v", err)
+	}
+
+	after, err := rpc.Stats(ctx, &ssepb.StatsRequest{Scheme: ssepb.Scheme_SCHEME_OURSCHEME})
+	if err != nil {
+		t.Fatalf("Stats returned an error: %v", err)
+	}
+	if after.Entries != before.Entries {
+		t.Errorf("server EDB changed by rejected uploads: before %d, after %d", before.Entries, after.Entries)
+	}
+	result, err := ours.Search(ctx, [2]string{"1", "3"})
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	sort.Ints(result)
+	if expected := []int{1, 2, 3, 4, 5, 6, 7}; !reflect.DeepEqual(result, expected) {
+		t.Errorf("Search after rejected uploads mismatch: expected %v, got %v", expected, result)
+	}
+}
+
+// TestRemoteVerifiable 可验证模式下 MAC 与证明经 gRPC 往返后查询通过验证，服务器篡改条目后查询返回 *VerificationError
+func TestRemoteVerifiable(t *testing.T) {
+	invertedIndex := map[string][]int{
+		"1": {1, 3},
+		"2": {4, 2, 5},
+		"3": {6, 7},
+		"4": {8, 9, 10, 11},
+		"5": {12, 13, 14, 15, 16},
+	}
+	sortedKeywords := []string{"1", "2", "3", "4", "5"}
+
+	ctx := context.Background()
+	server := NewServer(10, 64)
+	conn := dialBufconn(t, server)
+	oursClient := OurScheme.NewClient(10)
+	oursClient.Verifiable = true
+	ours := NewOurSchemeClient(oursClient, conn)
+	fbParams := FB_RSSE.Setup(64)
+	fbParams.Verifiable = true
+	fb := NewFBClient(fbParams, conn)
+	if err := ours.BuildIndex(ctx, invertedIndex, sortedKeywords); err != nil {
+		t.Fatalf("OurScheme BuildIndex returned an error: %v", err)
+	}
+	if err := fb.BuildIndex(ctx, invertedIndex, sortedKeywords); err != nil {
+		t.Fatalf("FB_RSSE BuildIndex returned an error: %v", err)
+	}
+	if err := ours.Update(ctx, "3", []*big.Int{big.NewInt(20)}); err != nil {
+		t.Fatalf("OurScheme Update returned an error: %v", err)
+	}
+	if err := fb.Update(ctx, "3", []int{20}); err != nil {
+		t.Fatalf("FB_RSSE Update returned an error: %v", err)
+	}
+
+	searches := map[string]func(context.Context, [2]string) ([]int, error){
+		"OurScheme": ours.Search,
+		"FB_RSSE":   fb.Search,
+	}
+	for name, search := range searches {
+		result, err := search(ctx, [2]string{"2", "4"})
+		if err != nil {
+			t.Fatalf("%s verified Search returned an error: %v", name, err)
+		}
+		sort.Ints(result)
+		if expected := []int{2, 4, 5, 6, 7, 8, 9, 10, 11, 20}; !reflect.DeepEqual(result, expected) {
+			t.Errorf("%s verified Search mismatch: expected %v, got %v", name, expected, result)
+		}
+	}
+
+	// 服务器篡改所有条目的密文，MAC 保持不变
+	server.mu.Lock()
+	for token, value := range server.ours.EDB {
+		tampered := append([]byte{}, value...)
+		tampered[len(tampered)-1] ^= 1
+		server.ours.EDB[token] = tampered
+	}
+	for UT, data := range server.fb.EDB {
+		data.BigIntValue = new(big.Int).Add(data.BigIntValue, big.NewInt(1))
+		server.fb.EDB[UT] = data
+	}
+	server.mu.Unlock()
+	for name, search := range searches {
+		_, err := search(ctx, [2]string{"2", "4"})
+		var oursErr *OurScheme.VerificationError
+		var fbErr *FB_RSSE.VerificationError
+		if !errors.As(err, &oursErr) && !errors.As(err, &fbErr) {
+			t.Errorf("%s Search over a tampered EDB: expected a verification error, got %v", name, err)
+		}
+	}
+}
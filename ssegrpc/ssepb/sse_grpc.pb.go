@@ -0,0 +1,279 @@
+// 加密范围搜索的服务器端 gRPC 接口，同时承载 OurScheme 与 FB_RSSE 的 EDB
+//
+// 生成代码：
+//
+//	protoc --go_out=. --go_opt=paths=source_relative \
+//	       --go-grpc_out=. --go-grpc_opt=paths=source_relative sse.proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: sse.proto
+
+package ssepb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	SSE_BuildIndex_FullMethodName = "/sse.v1.SSE/BuildIndex"
+	SSE_Search_FullMethodName     = "/sse.v1.SSE/Search"
+	SSE_Update_FullMethodName     = "/sse.v1.SSE/Update"
+	SSE_Delete_FullMethodName     = "/sse.v1.SSE/Delete"
+	SSE_Stats_FullMethodName      = "/sse.v1.SSE/Stats"
+)
+
+// SSEClient is the client API for SSE service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SSEClient interface {
+	BuildIndex(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[BuildIndexRequest, BuildIndexResponse], error)
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SearchResult], error)
+	Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*UpdateResponse, error)
+	// Delete 写入客户端删除文件后生成的条目，与 Update 的处理相同，只单独计数
+	Delete(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*UpdateResponse, error)
+	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error)
+}
+
+type sSEClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSSEClient(cc grpc.ClientConnInterface) SSEClient {
+	return &sSEClient{cc}
+}
+
+func (c *sSEClient) BuildIndex(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[BuildIndexRequest, BuildIndexResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SSE_ServiceDesc.Streams[0], SSE_BuildIndex_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[BuildIndexRequest, BuildIndexResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SSE_BuildIndexClient = grpc.ClientStreamingClient[BuildIndexRequest, BuildIndexResponse]
+
+func (c *sSEClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SearchResult], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SSE_ServiceDesc.Streams[1], SSE_Search_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SearchRequest, SearchResult]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SSE_SearchClient = grpc.ServerStreamingClient[SearchResult]
+
+func (c *sSEClient) Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*UpdateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateResponse)
+	err := c.cc.Invoke(ctx, SSE_Update_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sSEClient) Delete(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*UpdateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateResponse)
+	err := c.cc.Invoke(ctx, SSE_Delete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sSEClient) Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StatsResponse)
+	err := c.cc.Invoke(ctx, SSE_Stats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SSEServer is the server API for SSE service.
+// All implementations must embed UnimplementedSSEServer
+// for forward compatibility.
+type SSEServer interface {
+	BuildIndex(grpc.ClientStreamingServer[BuildIndexRequest, BuildIndexResponse]) error
+	Search(*SearchRequest, grpc.ServerStreamingServer[SearchResult]) error
+	Update(context.Context, *UpdateRequest) (*UpdateResponse, error)
+	// Delete 写入客户端删除文件后生成的条目，与 Update 的处理相同，只单独计数
+	Delete(context.Context, *UpdateRequest) (*UpdateResponse, error)
+	Stats(context.Context, *StatsRequest) (*StatsResponse, error)
+	mustEmbedUnimplementedSSEServer()
+}
+
+// UnimplementedSSEServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSSEServer struct{}
+
+func (UnimplementedSSEServer) BuildIndex(grpc.ClientStreamingServer[BuildIndexRequest, BuildIndexResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method BuildIndex not implemented")
+}
+func (UnimplementedSSEServer) Search(*SearchRequest, grpc.ServerStreamingServer[SearchResult]) error {
+	return status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedSSEServer) Update(context.Context, *UpdateRequest) (*UpdateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
+}
+func (UnimplementedSSEServer) Delete(context.Context, *UpdateRequest) (*UpdateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedSSEServer) Stats(context.Context, *StatsRequest) (*StatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stats not implemented")
+}
+func (UnimplementedSSEServer) mustEmbedUnimplementedSSEServer() {}
+func (UnimplementedSSEServer) testEmbeddedByValue()             {}
+
+// UnsafeSSEServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SSEServer will
+// result in compilation errors.
+type UnsafeSSEServer interface {
+	mustEmbedUnimplementedSSEServer()
+}
+
+func RegisterSSEServer(s grpc.ServiceRegistrar, srv SSEServer) {
+	// If the following call pancis, it indicates UnimplementedSSEServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SSE_ServiceDesc, srv)
+}
+
+func _SSE_BuildIndex_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SSEServer).BuildIndex(&grpc.GenericServerStream[BuildIndexRequest, BuildIndexResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SSE_BuildIndexServer = grpc.ClientStreamingServer[BuildIndexRequest, BuildIndexResponse]
+
+func _SSE_Search_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SearchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SSEServer).Search(m, &grpc.GenericServerStream[SearchRequest, SearchResult]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SSE_SearchServer = grpc.ServerStreamingServer[SearchResult]
+
+func _SSE_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SSEServer).Update(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SSE_Update_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SSEServer).Update(ctx, req.(*UpdateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SSE_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SSEServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SSE_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SSEServer).Delete(ctx, req.(*UpdateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SSE_Stats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SSEServer).Stats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SSE_Stats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SSEServer).Stats(ctx, req.(*StatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SSE_ServiceDesc is the grpc.ServiceDesc for SSE service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SSE_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "sse.v1.SSE",
+	HandlerType: (*SSEServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Update",
+			Handler:    _SSE_Update_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _SSE_Delete_Handler,
+		},
+		{
+			MethodName: "Stats",
+			Handler:    _SSE_Stats_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "BuildIndex",
+			Handler:       _SSE_BuildIndex_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Search",
+			Handler:       _SSE_Search_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "sse.proto",
+}
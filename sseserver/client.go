@@ -0,0 +1,130 @@
+package sseserver
+
+import (
+	"EfficientAndLowStroageSSE/VH_RSSE/OurScheme"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sync/atomic"
+)
+
+// Stats 客户端与服务器之间的通信开销（HTTP 请求体与响应体的字节数）
+type Stats struct {
+	RoundTrips    int64
+	BytesSent     int64
+	BytesReceived int64
+}
+
+// Client 远程客户端：在本地执行 GenToken/LocalSearch，通过 HTTP 与服务器交互
+type Client struct {
+	*OurScheme.Client
+	BaseURL    string
+	HTTPClient *http.Client
+
+	roundTrips    atomic.Int64
+	bytesSent     atomic.Int64
+	bytesReceived atomic.Int64
+}
+
+// NewClient 创建连接到 baseURL 的客户端，httpClient 为 nil 时使用 http.DefaultClient
+func NewClient(client *OurScheme.Client, baseURL string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{Client: client, BaseURL: baseURL, HTTPClient: httpClient}
+}
+
+// BuildIndex 在本地构建索引并将全部加密条目上传到服务器
+func (c *Client) BuildIndex(invertedIndex map[string][]int, keywords []string) error {
+	req, err := c.Client.BuildIndex(invertedIndex, keywords)
+	if err != nil {
+		return err
+	}
+	return c.post("/upload", req, nil)
+}
+
+// Update 在本地生成更新消息并发送到服务器
+func (c *Client) Update(w string, docID []*big.Int) error {
+	req, err := c.Client.Update(w, docID)
+	if err != nil {
+		return err
+	}
+	return c.post("/update", req, nil)
+}
+
+// Delete 在本地生成删除消息并发送到服务器
+func (c *Client) Delete(w string, docID []*big.Int) error {
+	req, err := c.Client.Delete(w, docID)
+	if err != nil {
+		return err
+	}
+	return c.post("/update", req, nil)
+}
+
+// BatchUpdate 在本地生成批量更新消息，并以一次请求发送到服务器
+func (c *Client) BatchUpdate(updates []OurScheme.Update) error {
+	req, err := c.Client.BatchUpdate(updates)
+	if err != nil {
+		return err
+	}
+	return c.post("/update", req, nil)
+}
+
+// Search 完成一次远程范围查询，返回查询范围内的文件 ID
+func (c *Client) Search(queryRange [2]string) ([]int, error) {
+	query, err := c.GenToken(queryRange)
+	if err != nil {
+		return nil, err
+	}
+	if query.Empty {
+		return []int{}, nil
+	}
+	var resp OurScheme.SearchResponse
+	if err := c.post("/search", query.Request(), &resp); err != nil {
+		return nil, err
+	}
+	return c.LocalSearch(query, &resp)
+}
+
+// Stats 返回累计的通信开销
+func (c *Client) Stats() Stats {
+	return Stats{
+		RoundTrips:    c.roundTrips.Load(),
+		BytesSent:     c.bytesSent.Load(),
+		BytesReceived: c.bytesReceived.Load(),
+	}
+}
+
+// post 以 JSON 发送请求，out 不为 nil 时解析 JSON 响应
+func (c *Client) post(path string, in interface{}, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("编码请求失败: %v", err)
+	}
+	resp, err := c.HTTPClient.Post(c.BaseURL+path, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("读取响应失败: %v", err)
+	}
+	c.roundTrips.Add(1)
+	c.bytesSent.Add(int64(len(body)))
+	c.bytesReceived.Add(int64(len(data)))
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("服务器返回 %s: %s", resp.Status, bytes.TrimSpace(data))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("解析响应失败: %v", err)
+	}
+	return nil
+}
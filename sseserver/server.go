@@ -0,0 +1,106 @@
+// Package sseserver 通过 HTTP/JSON 提供 OurScheme 的服务器端协议，并提供对应的远程客户端
+//
+// 接口：
+//
+//	POST /upload  UpdateRequest   用客户端 BuildIndex 生成的条目替换整个 EDB
+//	POST /update  UpdateRequest   将更新条目写入 EDB
+//	POST /search  SearchRequest -> SearchResponse
+package sseserver
+
+import (
+	"EfficientAndLowStroageSSE/VH_RSSE/OurScheme"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// maxBodySize 请求体大小上限
+const maxBodySize = 1 << 30
+
+// Server 持有 EDB 的 HTTP 服务器，可直接作为 http.Handler 使用
+type Server struct {
+	mu     sync.RWMutex
+	L      int
+	server *OurScheme.Server
+	mux    *http.ServeMux
+}
+
+// NewServer 创建位图长度为 L 的服务器
+func NewServer(L int) *Server {
+	s := &Server{
+		L:      L,
+		server: OurScheme.NewServer(L),
+		mux:    http.NewServeMux(),
+	}
+	s.mux.HandleFunc("POST /upload", s.handleUpload)
+	s.mux.HandleFunc("POST /update", s.handleUpdate)
+	s.mux.HandleFunc("POST /search", s.handleSearch)
+	return s
+}
+
+// ServeHTTP 实现 http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Len 返回 EDB 中的条目数
+func (s *Server) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.server.EDB)
+}
+
+func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request) {
+	var req OurScheme.UpdateRequest
+	if err := decodeBody(w, r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	server := OurScheme.NewServer(s.L)
+	if err := server.ApplyUpdate(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	s.server = server
+	s.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	var req OurScheme.UpdateRequest
+	if err := decodeBody(w, r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	err := s.server.ApplyUpdate(&req)
+	s.mu.Unlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	var req OurScheme.SearchRequest
+	if err := decodeBody(w, r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.RLock()
+	resp := s.server.Search(&req)
+	s.mu.RUnlock()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// decodeBody 解析 JSON 请求体
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(v); err != nil {
+		return fmt.Errorf("解析请求失败: %v", err)
+	}
+	return nil
+}
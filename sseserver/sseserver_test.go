@@ -0,0 +1,118 @@
+package sseserver
+
+import (
+	"EfficientAndLowStroageSSE/VH_RSSE/OurScheme"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// TestRemoteSearch 客户端经 HTTP 上传索引并查询，结果与明文一致，且记录通信开销
+func TestRemoteSearch(t *testing.T) {
+	invertedIndex := map[string][]int{
+		"1": {1, 3},
+		"2": {4, 2, 5},
+		"3": {6, 7},
+		"4": {8, 9, 10, 11},
+		"5": {12, 13, 14, 15, 16},
+	}
+	sortedKeywords := []string{"1", "2", "3", "4", "5"}
+
+	server := NewServer(10)
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+	client := NewClient(OurScheme.NewClient(10), ts.URL, ts.Client())
+
+	if err := client.BuildIndex(invertedIndex, sortedKeywords); err != nil {
+		t.Fatalf("BuildIndex returned an error: %v", err)
+	}
+	if server.Len() != len(invertedIndex) {
+		t.Fatalf("EDB size mismatch: expected %d, got %d", len(invertedIndex), server.Len())
+	}
+	uploaded := client.Stats()
+
+	queries := map[[2]string][]int{
+		{"1", "5"}: {1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+		{"2", "4"}: {2, 4, 5, 6, 7, 8, 9, 10, 11},
+		{"3", "3"}: {6, 7},
+	}
+	for queryRange, expected := range queries {
+		result, err := client.Search(queryRange)
+		if err != nil {
+			t.Fatalf("Search %v returned an error: %v", queryRange, err)
+		}
+		sort.Ints(result)
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("Search %v mismatch: expected %v, got %v", queryRange, expected, result)
+		}
+	}
+
+	stats := client.Stats()
+	if stats.RoundTrips != uploaded.RoundTrips+int64(len(queries)) {
+		t.Errorf("round trips mismatch: expected %d, got %d", uploaded.RoundTrips+int64(len(queries)), stats.RoundTrips)
+	}
+	if stats.BytesSent <= uploaded.BytesSent || stats.BytesReceived <= uploaded.BytesReceived {
+		t.Errorf("search traffic not recorded: before %+v, after %+v", uploaded, stats)
+	}
+
+	if err := client.Delete("3", []*big.Int{big.NewInt(6)}); err != nil {
+		t.Fatalf("Delete returned an error: %v", err)
+	}
+	result, err := client.Search([2]string{"2", "4"})
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	sort.Ints(result)
+	if expected := []int{2, 4, 5, 7, 8, 9, 10, 11}; !reflect.DeepEqual(result, expected) {
+		t.Errorf("Search after Delete mismatch: expected %v, got %v", expected, result)
+	}
+
+	if err := client.Update("3", []*big.Int{big.NewInt(20)}); err != nil {
+		t.Errorf("Update returned an error: %v", err)
+	}
+
+	roundTrips := client.Stats().RoundTrips
+	err = client.BatchUpdate([]OurScheme.Update{
+		{Op: OurScheme.OpAdd, Keyword: "2", DocIDs: []*big.Int{big.NewInt(21)}},
+		{Op: OurScheme.OpAdd, Keyword: "4", DocIDs: []*big.Int{big.NewInt(22)}},
+		{Op: OurScheme.OpDel, Keyword: "3", DocIDs: []*big.Int{big.NewInt(7)}},
+	})
+	if err != nil {
+		t.Fatalf("BatchUpdate returned an error: %v", err)
+	}
+	if actual := client.Stats().RoundTrips - roundTrips; actual != 1 {
+		t.Errorf("BatchUpdate round trips mismatch: expected 1, got %d", actual)
+	}
+	result, err = client.Search([2]string{"2", "4"})
+	if err != nil {
+		t.Fatalf("Search returned an error: %v", err)
+	}
+	sort.Ints(result)
+	if expected := []int{2, 4, 5, 8, 9, 10, 11, 20, 21, 22}; !reflect.DeepEqual(result, expected) {
+		t.Errorf("Search after BatchUpdate mismatch: expected %v, got %v", expected, result)
+	}
+}
+
+// TestServer_badRequest 非法请求返回错误状态码，客户端将其转换为错误
+func TestServer_badRequest(t *testing.T) {
+	ts := httptest.NewServer(NewServer(10))
+	defer ts.Close()
+
+	resp, err := ts.Client().Post(ts.URL+"/search", "application/json", strings.NewReader("{"))
+	if err != nil {
+		t.Fatalf("Post returned an error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("status mismatch: expected %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+
+	client := NewClient(OurScheme.NewClient(10), ts.URL, ts.Client())
+	if err := client.post("/missing", OurScheme.NewUpdateRequest(), nil); err == nil {
+		t.Errorf("post to an unknown path should fail")
+	}
+}
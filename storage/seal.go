@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// SealMagic 加密客户端状态的文件头魔数
+//
+// 文件布局（整数均为大端序）：
+//
+//	magic   [8]byte  "ELSSECLI"
+//	version uint16   当前为 SealVersion
+//	scheme  uint8    方案编号
+//	salt    [16]byte Argon2id 盐
+//	time    uint32   Argon2id 迭代次数
+//	memory  uint32   Argon2id 内存（KiB）
+//	threads uint8    Argon2id 并行度
+//	nonce   [12]byte AES-GCM 随机数
+//	sealed  []byte   AES-GCM 密文与认证标签，以上全部字段作为附加数据参与认证
+const SealMagic = "ELSSECLI"
+
+// SealVersion 客户端状态文件的格式版本，与 EDB 格式的 Version 相互独立
+const SealVersion uint16 = 1
+
+// KDFParams Argon2id 参数
+type KDFParams struct {
+	Time    uint32
+	Memory  uint32 // KiB
+	Threads uint8
+}
+
+// DefaultKDFParams 导出客户端状态时使用的 Argon2id 参数（RFC 9106 推荐的低内存配置）
+var DefaultKDFParams = KDFParams{Time: 3, Memory: 64 * 1024, Threads: 4}
+
+const (
+	saltSize = 16
+	keySize  = 32
+)
+
+// Seal 用口令派生的密钥加密并认证 plaintext，写出完整的文件
+func Seal(w io.Writer, password []byte, scheme uint8, plaintext []byte) error {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	params := DefaultKDFParams
+
+	var header bytes.Buffer
+	header.WriteString(SealMagic)
+	for _, v := range []interface{}{SealVersion, scheme, salt, params.Time, params.Memory, params.Threads} {
+		binary.Write(&header, binary.BigEndian, v)
+	}
+
+	aead, err := newAEAD(password, salt, params)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	header.Write(nonce)
+
+	sealed := aead.Seal(nil, nonce, plaintext, header.Bytes())
+	if _, err := w.Write(header.Bytes()); err != nil {
+		return err
+	}
+	_, err = w.Write(sealed)
+	return err
+}
+
+// Open 校验并解密 Seal 写出的数据，口令错误或数据被篡改时返回错误
+func Open(r io.Reader, password []byte, scheme uint8) ([]byte, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	// magic + version + scheme + salt + time + memory + threads
+	fixedSize := len(SealMagic) + 2 + 1 + saltSize + 4 + 4 + 1
+	if len(data) < fixedSize {
+		return nil, fmt.Errorf("客户端状态文件过短: %d 字节", len(data))
+	}
+	if string(data[:len(SealMagic)]) != SealMagic {
+		return nil, fmt.Errorf("不是客户端状态文件: 魔数为 %q", data[:len(SealMagic)])
+	}
+	reader := bytes.NewReader(data[len(SealMagic):])
+	var version uint16
+	var fileScheme uint8
+	salt := make([]byte, saltSize)
+	var params KDFParams
+	for _, v := range []interface{}{&version, &fileScheme, salt, &params.Time, &params.Memory, &params.Threads} {
+		binary.Read(reader, binary.BigEndian, v)
+	}
+	if version != SealVersion {
+		return nil, fmt.Errorf("不支持的客户端状态版本 %d，当前版本为 %d", version, SealVersion)
+	}
+	if fileScheme != scheme {
+		return nil, fmt.Errorf("方案编号不匹配: 文件为 %d，期望 %d", fileScheme, scheme)
+	}
+	// 限制参数范围，防止篡改的文件让 Argon2id 耗尽内存
+	if params.Time == 0 || params.Time > 16 || params.Threads == 0 || params.Memory > 1<<21 {
+		return nil, fmt.Errorf("客户端状态文件中的 Argon2id 参数无效: %+v", params)
+	}
+
+	aead, err := newAEAD(password, salt, params)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < fixedSize+aead.NonceSize() {
+		return nil, fmt.Errorf("客户端状态文件过短: %d 字节", len(data))
+	}
+	headerSize := fixedSize + aead.NonceSize()
+	nonce := data[fixedSize:headerSize]
+	plaintext, err := aead.Open(nil, nonce, data[headerSize:], data[:headerSize])
+	if err != nil {
+		return nil, fmt.Errorf("客户端状态认证失败（口令错误或文件被篡改）")
+	}
+	return plaintext, nil
+}
+
+// newAEAD 由口令经 Argon2id 派生 AES-256-GCM 密钥
+func newAEAD(password, salt []byte, params KDFParams) (cipher.AEAD, error) {
+	key := argon2.IDKey(password, salt, params.Time, params.Memory, params.Threads, keySize)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,129 @@
+// Package storage 定义加密数据库落盘使用的版本化二进制格式，以及客户端状态的口令加密封装（见 seal.go）
+//
+// 文件布局（整数均为大端序）：
+//
+//	magic   [8]byte  "ELSSEEDB"
+//	version uint16   当前为 Version
+//	scheme  uint8    方案编号（SchemeOurScheme / SchemeFBRSSE）
+//	param   uint64   方案参数（OurScheme 为 L，FB_RSSE 为 BsLength）
+//	count   uint64   条目数
+//	entries          每个条目由方案自行定义的若干个字段组成，字段为 uint32 长度 + 内容；
+//	                 版本 2 起每个条目的最后一个字段为可验证模式下条目的 MAC，未启用时为空
+package storage
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Magic 文件头魔数
+const Magic = "ELSSEEDB"
+
+// Version 当前格式版本，版本 1 的条目不含 MAC 字段，不再支持读取
+const Version uint16 = 2
+
+// 方案编号
+const (
+	SchemeOurScheme uint8 = 1
+	SchemeFBRSSE    uint8 = 2
+)
+
+// maxFieldSize 单个字段的长度上限，防止损坏的文件导致巨量内存分配
+const maxFieldSize = 1 << 30
+
+// Header 文件头
+type Header struct {
+	Scheme uint8
+	Param  uint64
+	Count  uint64
+}
+
+// Writer 按格式写出 EDB
+type Writer struct {
+	w *bufio.Writer
+}
+
+// NewWriter 写出文件头并返回用于写条目的 Writer，写完后需调用 Flush
+func NewWriter(w io.Writer, header Header) (*Writer, error) {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString(Magic); err != nil {
+		return nil, err
+	}
+	for _, v := range []interface{}{Version, header.Scheme, header.Param, header.Count} {
+		if err := binary.Write(bw, binary.BigEndian, v); err != nil {
+			return nil, err
+		}
+	}
+	return &Writer{w: bw}, nil
+}
+
+// WriteField 写出一个长度前缀字段
+func (sw *Writer) WriteField(b []byte) error {
+	if len(b) > maxFieldSize {
+		return fmt.Errorf("字段长度 %d 超过上限 %d", len(b), maxFieldSize)
+	}
+	if err := binary.Write(sw.w, binary.BigEndian, uint32(len(b))); err != nil {
+		return err
+	}
+	_, err := sw.w.Write(b)
+	return err
+}
+
+// Flush 将缓冲区写入底层 io.Writer
+func (sw *Writer) Flush() error {
+	return sw.w.Flush()
+}
+
+// Reader 按格式读取 EDB
+type Reader struct {
+	r io.Reader
+	// Header 已读取的文件头
+	Header Header
+}
+
+// NewReader 读取并校验文件头，scheme 与文件中的方案编号不一致时返回错误
+func NewReader(r io.Reader, scheme uint8) (*Reader, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(Magic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, fmt.Errorf("读取文件头失败: %v", err)
+	}
+	if string(magic) != Magic {
+		return nil, fmt.Errorf("不是 EDB 文件: 魔数为 %q", magic)
+	}
+	var version uint16
+	if err := binary.Read(br, binary.BigEndian, &version); err != nil {
+		return nil, fmt.Errorf("读取版本号失败: %v", err)
+	}
+	if version != Version {
+		return nil, fmt.Errorf("不支持的 EDB 版本 %d，当前版本为 %d", version, Version)
+	}
+	sr := &Reader{r: br}
+	for _, v := range []interface{}{&sr.Header.Scheme, &sr.Header.Param, &sr.Header.Count} {
+		if err := binary.Read(br, binary.BigEndian, v); err != nil {
+			return nil, fmt.Errorf("读取文件头失败: %v", err)
+		}
+	}
+	if sr.Header.Scheme != scheme {
+		return nil, fmt.Errorf("方案编号不匹配: 文件为 %d，期望 %d", sr.Header.Scheme, scheme)
+	}
+	return sr, nil
+}
+
+// ReadField 读取一个长度前缀字段
+func (sr *Reader) ReadField() ([]byte, error) {
+	var n uint32
+	if err := binary.Read(sr.r, binary.BigEndian, &n); err != nil {
+		return nil, fmt.Errorf("读取字段长度失败: %v", err)
+	}
+	if n > maxFieldSize {
+		return nil, fmt.Errorf("字段长度 %d 超过上限 %d", n, maxFieldSize)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(sr.r, b); err != nil {
+		return nil, fmt.Errorf("读取字段内容失败: %v", err)
+	}
+	return b, nil
+}
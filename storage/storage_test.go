@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+// TestFormat_roundTrip 文件头与字段写出后可原样读回，魔数、版本或方案不符时报错
+func TestFormat_roundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	header := Header{Scheme: SchemeFBRSSE, Param: 64, Count: 2}
+	sw, err := NewWriter(&buf, header)
+	if err != nil {
+		t.Fatalf("NewWriter returned an error: %v", err)
+	}
+	fields := [][]byte{[]byte("token"), {}, {0x01, 0x02}}
+	for _, field := range fields {
+		if err := sw.WriteField(field); err != nil {
+			t.Fatalf("WriteField returned an error: %v", err)
+		}
+	}
+	if err := sw.Flush(); err != nil {
+		t.Fatalf("Flush returned an error: %v", err)
+	}
+	data := buf.Bytes()
+
+	sr, err := NewReader(bytes.NewReader(data), SchemeFBRSSE)
+	if err != nil {
+		t.Fatalf("NewReader returned an error: %v", err)
+	}
+	if sr.Header != header {
+		t.Errorf("header mismatch: expected %+v, got %+v", header, sr.Header)
+	}
+	for _, expected := range fields {
+		actual, err := sr.ReadField()
+		if err != nil {
+			t.Fatalf("ReadField returned an error: %v", err)
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("field mismatch: expected %v, got %v", expected, actual)
+		}
+	}
+	if _, err := sr.ReadField(); err == nil {
+		t.Errorf("ReadField past the end should fail")
+	}
+
+	if _, err := NewReader(bytes.NewReader(data), SchemeOurScheme); err == nil {
+		t.Errorf("NewReader with a different scheme should fail")
+	}
+	badVersion := append([]byte{}, data...)
+	badVersion[len(Magic)+1]++
+	if _, err := NewReader(bytes.NewReader(badVersion), SchemeFBRSSE); err == nil {
+		t.Errorf("NewReader with an unknown version should fail")
+	}
+	// 版本 1 的条目不含 MAC 字段，须明确拒绝而不是按当前格式读取
+	oldVersion := append([]byte{}, data...)
+	binary.BigEndian.PutUint16(oldVersion[len(Magic):], 1)
+	if _, err := NewReader(bytes.NewReader(oldVersion), SchemeFBRSSE); err == nil {
+		t.Errorf("NewReader with version 1 should fail")
+	}
+	badMagic := append([]byte{}, data...)
+	badMagic[0] = 'X'
+	if _, err := NewReader(bytes.NewReader(badMagic), SchemeFBRSSE); err == nil {
+		t.Errorf("NewReader with a bad magic should fail")
+	}
+}
+
+// TestSeal_integrity 口令正确时原样解密，口令错误、任意字节被篡改或方案不符时报错
+func TestSeal_integrity(t *testing.T) {
+	password := []byte("password")
+	plaintext := []byte("client state")
+	var buf bytes.Buffer
+	if err := Seal(&buf, password, SchemeOurScheme, plaintext); err != nil {
+		t.Fatalf("Seal returned an error: %v", err)
+	}
+	sealed := buf.Bytes()
+	if bytes.Contains(sealed, plaintext) {
+		t.Errorf("sealed data contains the plaintext")
+	}
+
+	opened, err := Open(bytes.NewReader(sealed), password, SchemeOurScheme)
+	if err != nil {
+		t.Fatalf("Open returned an error: %v", err)
+	}
+	if !bytes.Equal(opened, plaintext) {
+		t.Errorf("plaintext mismatch: expected %q, got %q", plaintext, opened)
+	}
+
+	if _, err := Open(bytes.NewReader(sealed), []byte("wrong"), SchemeOurScheme); err == nil {
+		t.Errorf("Open with a wrong password should fail")
+	}
+	if _, err := Open(bytes.NewReader(sealed), password, SchemeFBRSSE); err == nil {
+		t.Errorf("Open with a different scheme should fail")
+	}
+	// 篡改盐（头部）和密文（尾部）
+	for _, i := range []int{len(SealMagic) + 3, len(sealed) - 1} {
+		tampered := append([]byte{}, sealed...)
+		tampered[i] ^= 0x01
+		if _, err := Open(bytes.NewReader(tampered), password, SchemeOurScheme); err == nil {
+			t.Errorf("Open of data tampered at byte %d should fail", i)
+		}
+	}
+}
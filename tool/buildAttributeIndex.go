@@ -0,0 +1,47 @@
+package tool
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// 原始签到文件（制表符分隔）中各字段的位置
+const (
+	FieldUser      = 0
+	FieldTime      = 1
+	FieldLatitude  = 2
+	FieldLongitude = 3
+	FieldLocation  = 4
+)
+
+// BuildAttributeIndex 从原始签到文件构建第二个属性的精确匹配倒排索引：
+// 关键词为第 field 个字段的取值（如 FieldLocation），文件 ID 为从 0 开始的行号，与 BuildInvertedIndex2D 一致
+func BuildAttributeIndex(filePath string, field int) (InvertedIndex, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	invertedIndex := make(InvertedIndex)
+	scanner := bufio.NewScanner(file)
+	rowID := 0
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		rowID++
+
+		fields := strings.Split(line, "\t")
+		if len(fields) != 5 || fields[field] == "" {
+			continue
+		}
+		value := fields[field]
+		invertedIndex[value] = append(invertedIndex[value], rowID-1)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return invertedIndex, nil
+}
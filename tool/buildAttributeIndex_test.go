@@ -0,0 +1,29 @@
+package tool
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestBuildAttributeIndex 按地点字段建立的索引与行号对应，字段数不足的行被跳过
+func TestBuildAttributeIndex(t *testing.T) {
+	lines := "0\t2010-10-19T23:55:27Z\t30.2359091167\t-97.7951395833\t22847\n" +
+		"0\t2010-10-18T22:17:43Z\t30.2691029532\t-97.7493953705\t420315\n" +
+		"1\t2010-10-17T23:42:03Z\t30.2557309927\t-97.7633857727\n" +
+		"1\t2010-10-17T19:26:05Z\t30.2691029532\t-97.7493953705\t420315\n"
+	filePath := filepath.Join(t.TempDir(), "checkins.txt")
+	if err := os.WriteFile(filePath, []byte(lines), 0o644); err != nil {
+		t.Fatalf("WriteFile returned an error: %v", err)
+	}
+
+	invertedIndex, err := BuildAttributeIndex(filePath, FieldLocation)
+	if err != nil {
+		t.Fatalf("BuildAttributeIndex returned an error: %v", err)
+	}
+	expected := InvertedIndex{"22847": {0}, "420315": {1, 3}}
+	if !reflect.DeepEqual(invertedIndex, expected) {
+		t.Errorf("InvertedIndex mismatch: expected %v, got %v", expected, invertedIndex)
+	}
+}
@@ -0,0 +1,52 @@
+package tool
+
+import (
+	"EfficientAndLowStroageSSE/spatial"
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// BuildInvertedIndex2D 从原始签到文件（user, time, latitude, longitude, location，制表符分隔）
+// 构建二维倒排索引：关键词为 (纬度, 经度) 所在网格单元的 Morton 码，文件 ID 为从 0 开始的行号
+func BuildInvertedIndex2D(filePath string, grid spatial.Grid) (InvertedIndex, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	invertedIndex := make(InvertedIndex)
+	scanner := bufio.NewScanner(file)
+	rowID := 0
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		rowID++
+
+		fields := strings.Split(line, "\t")
+		if len(fields) != 5 {
+			continue
+		}
+		latitude, err := strconv.ParseFloat(fields[FieldLatitude], 64)
+		if err != nil {
+			continue
+		}
+		longitude, err := strconv.ParseFloat(fields[FieldLongitude], 64)
+		if err != nil {
+			continue
+		}
+		// 超出网格范围的坐标跳过
+		keyword, err := grid.Keyword(latitude, longitude)
+		if err != nil {
+			continue
+		}
+		invertedIndex[keyword] = append(invertedIndex[keyword], rowID-1)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return invertedIndex, nil
+}
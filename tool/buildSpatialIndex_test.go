@@ -0,0 +1,50 @@
+package tool
+
+import (
+	"EfficientAndLowStroageSSE/spatial"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestBuildInvertedIndex2D 同一网格单元内的签到归入同一关键词，格式错误和越界的行被跳过
+func TestBuildInvertedIndex2D(t *testing.T) {
+	lines := "0\t2010-10-19T23:55:27Z\t30.2359091167\t-97.7951395833\t22847\n" +
+		"0\t2010-10-18T22:17:43Z\t30.2691029532\t-97.7493953705\t420315\n" +
+		"1\t2010-10-17T23:42:03Z\t30.2557309927\t-97.7633857727\t316637\n" +
+		"1\t2010-10-17T19:26:05Z\tbad\t-97.7493953705\t420315\n" +
+		"2\t2010-10-16T18:50:42Z\t95.0\t-97.7493953705\t420315\n" +
+		"2\t2010-10-12T00:21:28Z\t30.2691029532\t-97.7493953705\n" +
+		"3\t2010-10-12T00:21:28Z\t30.2691029532\t-97.7493953705\t420315\n"
+	filePath := filepath.Join(t.TempDir(), "checkins.txt")
+	if err := os.WriteFile(filePath, []byte(lines), 0o644); err != nil {
+		t.Fatalf("WriteFile returned an error: %v", err)
+	}
+
+	grid := spatial.DefaultGrid()
+	invertedIndex, err := BuildInvertedIndex2D(filePath, grid)
+	if err != nil {
+		t.Fatalf("BuildInvertedIndex2D returned an error: %v", err)
+	}
+	expected := InvertedIndex{}
+	points := []struct {
+		id       int
+		lat, lon float64
+	}{
+		{0, 30.2359091167, -97.7951395833},
+		{1, 30.2691029532, -97.7493953705},
+		{2, 30.2557309927, -97.7633857727},
+		{6, 30.2691029532, -97.7493953705},
+	}
+	for _, point := range points {
+		keyword, err := grid.Keyword(point.lat, point.lon)
+		if err != nil {
+			t.Fatalf("Keyword returned an error: %v", err)
+		}
+		expected[keyword] = append(expected[keyword], point.id)
+	}
+	if !reflect.DeepEqual(invertedIndex, expected) {
+		t.Errorf("InvertedIndex mismatch: expected %v, got %v", expected, invertedIndex)
+	}
+}
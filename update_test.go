@@ -122,6 +122,26 @@ func TestUpdatePerformance(t *testing.T) {
 			avgFBDur := totalFBDur / updateTotalTimes
 			t.Logf("FB_DSSE 平均更新耗时=%d ns", avgFBDur)
 
+			// 批量更新测试：同样数量的更新合并为一条消息
+			oursBatch := make([]OurScheme.Update, updateTotalTimes)
+			fbBatch := make([]FB_RSSE.Update, updateTotalTimes)
+			for updateRound := 0; updateRound < updateTotalTimes; updateRound++ {
+				kw := strconv.Itoa(keywords[rand.Intn(len(keywords))])
+				oursBatch[updateRound] = OurScheme.Update{Op: OurScheme.OpAdd, Keyword: kw, DocIDs: generateRandomDocIDsBigInt(5)}
+				fbBatch[updateRound] = FB_RSSE.Update{Keyword: kw, DocIDs: []int{rand.Intn(FB_BsLen)}}
+			}
+			start := time.Now()
+			if err := ours.BatchUpdate(oursBatch); err != nil {
+				t.Fatalf("OurScheme BatchUpdate returned an error: %v", err)
+			}
+			avgOursBatchDur := time.Since(start).Nanoseconds() / updateTotalTimes
+			start = time.Now()
+			if _, err := fbDsseParams.BatchUpdate(fbBatch); err != nil {
+				t.Fatalf("FB_DSSE BatchUpdate returned an error: %v", err)
+			}
+			avgFBBatchDur := time.Since(start).Nanoseconds() / updateTotalTimes
+			t.Logf("OurScheme 批量更新平均耗时=%d ns, FB_DSSE 批量更新平均耗时=%d ns", avgOursBatchDur, avgFBBatchDur)
+
 			// 保存结果（无日志）
 			saveResult(resultsDir, indexNum[fileIndex], L, map[string]interface{}{
 				"keyword_count":   indexNum[fileIndex],
@@ -130,6 +150,8 @@ func TestUpdatePerformance(t *testing.T) {
 				"build_fb":        buildFBDur,
 				"update_ours_avg": avgOursDur,
 				"update_fb_avg":   avgFBDur,
+				"batch_ours_avg":  avgOursBatchDur,
+				"batch_fb_avg":    avgFBBatchDur,
 			})
 		}
 	}